@@ -34,12 +34,19 @@ func init() {
 	rootCmd.AddCommand(checkCmd)
 
 	// Output flags
-	checkCmd.Flags().StringVarP(&flagOutputFormat, "output-format", "f", "plaintext", "output format: plaintext, markdown, html, json")
+	checkCmd.Flags().StringVarP(&flagOutputFormat, "output-format", "f", "plaintext", "output format: plaintext, markdown, html, json, sarif, junit, csv, tsv, ndjson, template")
 	checkCmd.Flags().StringVarP(&flagOutputFile, "output-file", "o", "", "output file (default is stdout)")
+	checkCmd.Flags().StringVar(&flagGroupBy, "group-by", "status", "group the report by: status, page")
+	checkCmd.Flags().StringSliceVar(&flagColumns, "columns", nil, "csv and tsv columns, e.g. url,status,found_on (default url,status,status_code,found_on,error)")
+	checkCmd.Flags().BoolVar(&flagNoHeader, "no-header", false, "leave out the csv and tsv header row")
+	checkCmd.Flags().StringVar(&flagTemplate, "template", "", "Go template file for the report; .html files use html/template (implies --output-format template)")
 
 	// Behavior flags
 	checkCmd.Flags().IntVarP(&flagConcurrency, "concurrency", "c", 10, "number of concurrent checks")
 	checkCmd.Flags().IntVarP(&flagTimeout, "timeout", "t", 30, "timeout in seconds for each request")
+	checkCmd.Flags().StringVar(&flagMethod, "method", "head", "request method: head (with GET fallback), get, range")
+	checkCmd.Flags().StringVar(&flagCheckScope, "check-scope", "all", "which links to check: all, internal, external")
+	checkCmd.Flags().Float64Var(&flagRateLimit, "rate-limit", 0, "maximum requests per second per host (0 = unlimited)")
 	checkCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "verbose output")
 	checkCmd.Flags().BoolVar(&flagNoProgress, "no-progress", false, "disable progress display")
 	checkCmd.Flags().BoolVar(&flagStdin, "stdin", false, "read URLs from stdin")
@@ -10,6 +10,8 @@ var crawlCmd = &cobra.Command{
 	Short: "Crawl a website and check all discovered links",
 	Long: `Crawl a website starting from the given URL and check all discovered links.
 This command recursively follows links up to the specified depth and validates each one.
+Only pages on the start URL's host are followed; use --include and --exclude to narrow
+the crawl further. Links found on followed pages are checked wherever they point.
 
 Examples:
   # Crawl a website with default settings
@@ -22,8 +24,28 @@ Examples:
   unlinked crawl --concurrency=50 --max-depth=3 https://example.com
 
   # Crawl and save HTML report
-  unlinked crawl --output-format=html --output-file=report.html https://example.com`,
-	Args: cobra.ExactArgs(1),
+  unlinked crawl --output-format=html --output-file=report.html https://example.com
+
+  # Record progress, then pick up where an interrupted crawl stopped
+  unlinked crawl --state-file=crawl.state https://example.com
+  unlinked crawl --resume=crawl.state
+
+  # Seed the crawl from a sitemap that robots.txt does not list
+  unlinked crawl --sitemap=https://example.com/sitemaps/pages.xml.gz https://example.com
+
+  # Bound a nightly crawl: at most 5000 pages or one hour
+  unlinked crawl --max-pages=5000 --max-duration=3600 https://example.com
+
+  # Crawl only the docs, but still check every link found there
+  unlinked crawl --include=/docs/ --exclude=/docs/archive/ https://example.com/docs/`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		// A resumed crawl takes its start URL from the state file, and an
+		// explicit sitemap implies its site's root
+		if cmd.Flags().Changed("resume") || cmd.Flags().Changed("sitemap") {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Force crawler mode
 		cfg.Set("mode", types.ModeCrawler)
@@ -36,14 +58,38 @@ func init() {
 
 	// Crawler-specific flags
 	crawlCmd.Flags().IntVar(&flagMaxDepth, "max-depth", 3, "maximum crawl depth")
+	crawlCmd.Flags().StringVar(&flagStateFile, "state-file", "", "record crawl progress to this file so it can be resumed")
+	crawlCmd.Flags().StringVar(&flagResume, "resume", "", "resume an interrupted crawl from its state file")
+	crawlCmd.Flags().StringSliceVar(&flagSitemaps, "sitemap", nil, "sitemap to seed the crawl from instead of robots.txt or /sitemap.xml (repeatable)")
+	crawlCmd.Flags().BoolVar(&flagNoSitemap, "no-sitemap", false, "do not seed the crawl from sitemaps")
+	crawlCmd.Flags().StringSliceVar(&flagInclude, "include", nil, "only follow pages matching this path prefix or glob (repeatable)")
+	crawlCmd.Flags().StringSliceVar(&flagExclude, "exclude", nil, "do not follow pages matching this path prefix or glob (repeatable)")
+	crawlCmd.Flags().BoolVar(&flagSubdomains, "subdomains", false, "also follow pages on subdomains of the start URL's domain")
+	crawlCmd.Flags().BoolVar(&flagNoNofollow, "ignore-nofollow", false, "follow rel=\"nofollow\" links and pages marked robots nofollow")
+	crawlCmd.Flags().BoolVar(&flagNoCanonical, "ignore-canonical", false, "follow the links of pages that declare another rel=canonical URL")
+	crawlCmd.Flags().IntVar(&flagMaxVariants, "max-query-variants", 0, "follow at most this many query strings per path (0 = unlimited)")
+
+	// Budget flags
+	crawlCmd.Flags().IntVar(&flagMaxPages, "max-pages", 0, "stop crawling after this many pages (0 = unlimited)")
+	crawlCmd.Flags().IntVar(&flagMaxLinks, "max-links", 0, "stop after checking this many links (0 = unlimited)")
+	crawlCmd.Flags().IntVar(&flagMaxDuration, "max-duration", 0, "stop after this many seconds (0 = unlimited)")
+	crawlCmd.Flags().IntVar(&flagMaxHostReqs, "max-host-requests", 0, "send at most this many requests to any one host (0 = unlimited)")
+	crawlCmd.Flags().Int64Var(&flagMaxBytes, "max-bytes", 0, "stop after downloading this many response bytes (0 = unlimited)")
 
 	// Output flags
-	crawlCmd.Flags().StringVarP(&flagOutputFormat, "output-format", "f", "plaintext", "output format: plaintext, markdown, html, json")
+	crawlCmd.Flags().StringVarP(&flagOutputFormat, "output-format", "f", "plaintext", "output format: plaintext, markdown, html, json, sarif, junit, csv, tsv, ndjson, template")
 	crawlCmd.Flags().StringVarP(&flagOutputFile, "output-file", "o", "", "output file (default is stdout)")
+	crawlCmd.Flags().StringVar(&flagGroupBy, "group-by", "status", "group the report by: status, page")
+	crawlCmd.Flags().StringSliceVar(&flagColumns, "columns", nil, "csv and tsv columns, e.g. url,status,found_on (default url,status,status_code,found_on,error)")
+	crawlCmd.Flags().BoolVar(&flagNoHeader, "no-header", false, "leave out the csv and tsv header row")
+	crawlCmd.Flags().StringVar(&flagTemplate, "template", "", "Go template file for the report; .html files use html/template (implies --output-format template)")
 
 	// Behavior flags
 	crawlCmd.Flags().IntVarP(&flagConcurrency, "concurrency", "c", 10, "number of concurrent checks")
 	crawlCmd.Flags().IntVarP(&flagTimeout, "timeout", "t", 30, "timeout in seconds for each request")
+	crawlCmd.Flags().StringVar(&flagMethod, "method", "head", "request method: head (with GET fallback), get, range")
+	crawlCmd.Flags().StringVar(&flagCheckScope, "check-scope", "all", "which links to check: all, internal, external")
+	crawlCmd.Flags().Float64Var(&flagRateLimit, "rate-limit", 0, "maximum requests per second per host (0 = unlimited)")
 	crawlCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "verbose output")
 	crawlCmd.Flags().BoolVar(&flagNoProgress, "no-progress", false, "disable progress display")
 }
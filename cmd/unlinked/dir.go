@@ -0,0 +1,74 @@
+package main
+
+import (
+	"github.com/sardonyx001/unlinked/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var dirCmd = &cobra.Command{
+	Use:   "dir [path]",
+	Short: "Check a static site build directory without a web server",
+	Long: `Check every HTML file in a static site build directory, such as the public/
+output of Hugo or the build/ output of Docusaurus, without serving it first.
+
+Links are resolved against file paths: a link to /docs/ is served by
+docs/index.html and, with clean URLs, a link to /about by about.html. Links to
+files that do not exist are reported as dead. External links are checked over
+HTTP as usual.
+
+Set --base-url to the URL the site is published at so that absolute links to
+it, and the site's sitemaps, resolve to the build directory too.
+
+Examples:
+  # Check a Hugo build
+  unlinked dir ./public
+
+  # Treat absolute links to the production site as local files
+  unlinked dir --base-url=https://example.com ./public
+
+  # Only check links between local pages
+  unlinked dir --check-scope=internal ./build`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Force directory mode; the crawl starts at the base URL
+		cfg.Set("mode", types.ModeDirectory)
+		cfg.Set("directory.root", args[0])
+		return runCheck(cmd, nil)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dirCmd)
+
+	// Directory-specific flags
+	dirCmd.Flags().StringVar(&flagBaseURL, "base-url", "http://localhost/", "URL the site is published at")
+	dirCmd.Flags().StringSliceVar(&flagIndexFiles, "index-file", []string{"index.html"}, "files that serve a directory URL, in order (repeatable)")
+	dirCmd.Flags().BoolVar(&flagNoCleanURLs, "no-clean-urls", false, "do not serve /page from page.html")
+	dirCmd.Flags().StringSliceVar(&flagInclude, "include", nil, "only crawl pages matching this path prefix or glob (repeatable)")
+	dirCmd.Flags().BoolVar(&flagNoNofollow, "ignore-nofollow", false, "follow rel=\"nofollow\" links and pages marked robots nofollow")
+	dirCmd.Flags().StringSliceVar(&flagExclude, "exclude", nil, "do not crawl pages matching this path prefix or glob (repeatable)")
+
+	// Budget flags
+	dirCmd.Flags().IntVar(&flagMaxPages, "max-pages", 0, "stop crawling after this many pages (0 = unlimited)")
+	dirCmd.Flags().IntVar(&flagMaxLinks, "max-links", 0, "stop after checking this many links (0 = unlimited)")
+	dirCmd.Flags().IntVar(&flagMaxDuration, "max-duration", 0, "stop after this many seconds (0 = unlimited)")
+	dirCmd.Flags().IntVar(&flagMaxHostReqs, "max-host-requests", 0, "send at most this many requests to any one host (0 = unlimited)")
+	dirCmd.Flags().Int64Var(&flagMaxBytes, "max-bytes", 0, "stop after downloading this many response bytes (0 = unlimited)")
+
+	// Output flags
+	dirCmd.Flags().StringVarP(&flagOutputFormat, "output-format", "f", "plaintext", "output format: plaintext, markdown, html, json, sarif, junit, csv, tsv, ndjson, template")
+	dirCmd.Flags().StringVarP(&flagOutputFile, "output-file", "o", "", "output file (default is stdout)")
+	dirCmd.Flags().StringVar(&flagGroupBy, "group-by", "status", "group the report by: status, page")
+	dirCmd.Flags().StringSliceVar(&flagColumns, "columns", nil, "csv and tsv columns, e.g. url,status,found_on (default url,status,status_code,found_on,error)")
+	dirCmd.Flags().BoolVar(&flagNoHeader, "no-header", false, "leave out the csv and tsv header row")
+	dirCmd.Flags().StringVar(&flagTemplate, "template", "", "Go template file for the report; .html files use html/template (implies --output-format template)")
+
+	// Behavior flags
+	dirCmd.Flags().IntVarP(&flagConcurrency, "concurrency", "c", 10, "number of concurrent checks")
+	dirCmd.Flags().IntVarP(&flagTimeout, "timeout", "t", 30, "timeout in seconds for each request")
+	dirCmd.Flags().StringVar(&flagMethod, "method", "head", "request method: head (with GET fallback), get, range")
+	dirCmd.Flags().StringVar(&flagCheckScope, "check-scope", "all", "which links to check: all, internal, external")
+	dirCmd.Flags().Float64Var(&flagRateLimit, "rate-limit", 0, "maximum requests per second per host (0 = unlimited)")
+	dirCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "verbose output")
+	dirCmd.Flags().BoolVar(&flagNoProgress, "no-progress", false, "disable progress display")
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"github.com/sardonyx001/unlinked/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var filesCmd = &cobra.Command{
+	Use:   "files [paths...]",
+	Short: "Check the links in Markdown and reStructuredText source files",
+	Long: `Check the links in .md, .mdx and .rst files. Directories are searched
+recursively, skipping hidden directories and node_modules; with no paths the
+current directory is searched.
+
+Inline, image, reference-style and autolinks are all checked. Relative links
+are resolved against the file they appear in and checked on disk, including
+#fragments pointing at Markdown headings. Remote links are checked over HTTP.
+Every result is reported at the file:line:column it was found.
+
+Examples:
+  # Check every Markdown and reStructuredText file under docs/
+  unlinked files docs/
+
+  # Check specific files
+  unlinked files README.md CONTRIBUTING.md
+
+  # Only check links between local files
+  unlinked files --check-scope=internal docs/`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Force files mode
+		cfg.Set("mode", types.ModeFiles)
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		return runCheck(cmd, args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(filesCmd)
+
+	// Output flags
+	filesCmd.Flags().StringVarP(&flagOutputFormat, "output-format", "f", "plaintext", "output format: plaintext, markdown, html, json, sarif, junit, csv, tsv, ndjson, template")
+	filesCmd.Flags().StringVarP(&flagOutputFile, "output-file", "o", "", "output file (default is stdout)")
+	filesCmd.Flags().StringVar(&flagGroupBy, "group-by", "status", "group the report by: status, page")
+	filesCmd.Flags().StringSliceVar(&flagColumns, "columns", nil, "csv and tsv columns, e.g. url,status,found_on (default url,status,status_code,found_on,error)")
+	filesCmd.Flags().BoolVar(&flagNoHeader, "no-header", false, "leave out the csv and tsv header row")
+	filesCmd.Flags().StringVar(&flagTemplate, "template", "", "Go template file for the report; .html files use html/template (implies --output-format template)")
+
+	// Behavior flags
+	filesCmd.Flags().IntVarP(&flagConcurrency, "concurrency", "c", 10, "number of concurrent checks")
+	filesCmd.Flags().IntVarP(&flagTimeout, "timeout", "t", 30, "timeout in seconds for each request")
+	filesCmd.Flags().StringVar(&flagMethod, "method", "head", "request method: head (with GET fallback), get, range")
+	filesCmd.Flags().StringVar(&flagCheckScope, "check-scope", "all", "which links to check: all, internal, external")
+	filesCmd.Flags().Float64Var(&flagRateLimit, "rate-limit", 0, "maximum requests per second per host (0 = unlimited)")
+	filesCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "verbose output")
+	filesCmd.Flags().BoolVar(&flagNoProgress, "no-progress", false, "disable progress display")
+}
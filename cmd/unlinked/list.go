@@ -61,6 +61,8 @@ func showCompactList() {
 	}{
 		{"check", "Check specific URLs for broken links"},
 		{"crawl", "Crawl a website and check all discovered links"},
+		{"dir", "Check a static site build directory without a web server"},
+		{"files", "Check the links in Markdown and reStructuredText files"},
 		{"version", "Print version information"},
 		{"completion", "Generate shell completion scripts"},
 		{"help", "Show help for any command"},
@@ -100,6 +102,14 @@ func showVerboseList() {
 	fmt.Printf("    %s\n", listStyles.Description.Render("Crawl a website and check all discovered links"))
 	fmt.Printf("    %s\n\n", listStyles.Description.Render("Example: unlinked crawl --max-depth=2 https://example.com"))
 
+	fmt.Printf("  %s\n", listStyles.Command.Render("dir [path]"))
+	fmt.Printf("    %s\n", listStyles.Description.Render("Check the HTML files of a static site build without a web server"))
+	fmt.Printf("    %s\n\n", listStyles.Description.Render("Example: unlinked dir --base-url=https://example.com ./public"))
+
+	fmt.Printf("  %s\n", listStyles.Command.Render("files [paths...]"))
+	fmt.Printf("    %s\n", listStyles.Description.Render("Check the links in .md, .mdx and .rst files, reported by file:line:column"))
+	fmt.Printf("    %s\n\n", listStyles.Description.Render("Example: unlinked files docs/"))
+
 	// Utility Commands
 	fmt.Println(listStyles.Category.Render("Utility Commands:"))
 	fmt.Printf("  %s\n", listStyles.Command.Render("version [--short]"))
@@ -120,7 +130,7 @@ func showVerboseList() {
 
 	fmt.Println(listStyles.Category.Render("Common Flags:"))
 	fmt.Printf("  %s\n", listStyles.Description.Render("--config          Config file path"))
-	fmt.Printf("  %s\n", listStyles.Description.Render("-f, --output-format   Output format (plaintext, markdown, html, json)"))
+	fmt.Printf("  %s\n", listStyles.Description.Render("-f, --output-format   Output format (plaintext, markdown, html, json, sarif, junit, csv, tsv, ndjson, template)"))
 	fmt.Printf("  %s\n", listStyles.Description.Render("-o, --output-file     Output file path"))
 	fmt.Printf("  %s\n", listStyles.Description.Render("-c, --concurrency     Number of concurrent checks"))
 	fmt.Printf("  %s\n", listStyles.Description.Render("-t, --timeout         Request timeout in seconds"))
@@ -4,8 +4,12 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/sardonyx001/unlinked/internal/checker"
@@ -21,15 +25,40 @@ var (
 	cfg     *config.Manager
 
 	// Flags
-	flagMode           string
-	flagOutputFormat   string
-	flagOutputFile     string
-	flagConcurrency    int
-	flagTimeout        int
-	flagMaxDepth       int
-	flagVerbose        bool
-	flagNoProgress     bool
-	flagStdin          bool
+	flagMode         string
+	flagOutputFormat string
+	flagOutputFile   string
+	flagConcurrency  int
+	flagTimeout      int
+	flagMaxDepth     int
+	flagMethod       string
+	flagRateLimit    float64
+	flagCheckScope   string
+	flagVerbose      bool
+	flagNoProgress   bool
+	flagStdin        bool
+	flagStateFile    string
+	flagResume       string
+	flagSitemaps     []string
+	flagNoSitemap    bool
+	flagBaseURL      string
+	flagIndexFiles   []string
+	flagNoCleanURLs  bool
+	flagGroupBy      string
+	flagColumns      []string
+	flagNoHeader     bool
+	flagTemplate     string
+	flagInclude      []string
+	flagExclude      []string
+	flagSubdomains   bool
+	flagMaxVariants  int
+	flagMaxPages     int
+	flagMaxLinks     int
+	flagMaxDuration  int
+	flagMaxHostReqs  int
+	flagMaxBytes     int64
+	flagNoNofollow   bool
+	flagNoCanonical  bool
 )
 
 var rootCmd = &cobra.Command{
@@ -42,6 +71,8 @@ and provides a beautiful terminal UI.
 Commands:
   check       Check specific URLs for broken links
   crawl       Crawl a website and check all discovered links
+  dir         Check a static site build directory without a web server
+  files       Check the links in Markdown and reStructuredText files
   version     Print version information
   completion  Generate shell completion scripts
   help        Show help for any command
@@ -54,6 +85,12 @@ Examples:
   # Crawl a website
   unlinked crawl https://example.com
 
+  # Check a static site build
+  unlinked dir ./public
+
+  # Check the links in Markdown docs
+  unlinked files docs/
+
   # Show version
   unlinked version
 
@@ -112,25 +149,82 @@ func runCheck(cmd *cobra.Command, args []string) error {
 	// Apply command-line flags to config
 	applyFlags(cmd)
 
+	// Catch a bad column or setting before a long check rather than after it
+	if err := output.ValidateColumns(cfg.Get().CSV.Columns); err != nil {
+		return err
+	}
+	if err := validateChoices(cfg.Get()); err != nil {
+		return err
+	}
+
 	// Collect URLs to check
 	urls, err := collectURLs(args)
 	if err != nil {
 		return fmt.Errorf("failed to collect URLs: %w", err)
 	}
 
+	// Create checker
+	c, err := checker.New(cfg.Get())
+	if err != nil {
+		return fmt.Errorf("failed to create checker: %w", err)
+	}
+	defer c.Close()
+
+	// Continue an interrupted crawl from its state file
+	if flagResume != "" {
+		seeds, err := c.ResumeJournal(flagResume)
+		if err != nil {
+			return err
+		}
+		if len(urls) == 0 {
+			urls = seeds
+		}
+	}
+
+	// Without a start URL, crawl the sites of the given sitemaps
+	if len(urls) == 0 {
+		urls = sitemapRoots(cfg.Get().Sitemaps)
+	}
+
+	// A build directory is crawled from the URL it is published at
+	if cfg.Get().Mode == types.ModeDirectory {
+		urls = []string{cfg.Get().Directory.BaseURL}
+	}
+
 	if len(urls) == 0 {
 		return fmt.Errorf("no URLs provided. Use --stdin to read from stdin or provide URLs as arguments")
 	}
 
-	// Create checker
-	c, err := checker.New(cfg.Get())
+	// Record crawl progress so it can be resumed
+	if flagStateFile != "" && flagResume == "" {
+		if err := c.StartJournal(flagStateFile, urls); err != nil {
+			return err
+		}
+	}
+
+	// Open the output up front so streaming formats can write while the check runs
+	formatter, err := newFormatter()
 	if err != nil {
-		return fmt.Errorf("failed to create checker: %w", err)
+		return err
+	}
+	w, err := openOutput()
+	if err != nil {
+		return err
+	}
+	defer closeOutput(w)
+
+	showProgress := cfg.Get().ShowProgress
+	if streamer, ok := formatter.(output.Streamer); ok {
+		c.SetResultCallback(streamer.Stream(w))
+		// The progress display would garble results streamed to the terminal
+		if w == os.Stdout {
+			showProgress = false
+		}
 	}
 
 	// Set up UI
 	var result *types.CheckResult
-	if cfg.Get().ShowProgress {
+	if showProgress {
 		result, err = runWithUI(c, urls)
 	} else {
 		result, err = runWithoutUI(c, urls)
@@ -141,22 +235,77 @@ func runCheck(cmd *cobra.Command, args []string) error {
 	}
 
 	// Format and output results
-	if err := outputResults(result); err != nil {
+	if err := formatter.Format(result, w); err != nil {
 		return fmt.Errorf("failed to output results: %w", err)
 	}
 
-	// Exit with error code if issues found
-	if result.TotalDead > 0 || result.TotalErrors > 0 {
+	// A state file missing entries cannot be resumed from
+	journalErr := c.Close()
+	if journalErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", journalErr)
+	}
+
+	// Exit with error code if issues found or the check did not finish
+	if result.Incomplete {
+		fmt.Fprintf(os.Stderr, "Warning: check stopped early (%s); results are partial\n", result.StopReason)
+		if state := stateFile(); state != "" && journalErr == nil {
+			fmt.Fprintf(os.Stderr, "Resume with: unlinked crawl --resume %s\n", state)
+		}
+	}
+	if result.TotalDead > 0 || result.TotalErrors > 0 || result.Incomplete {
+		closeOutput(w)
 		os.Exit(1)
 	}
 
 	return nil
 }
 
+// sitemapRoots returns the root URL of each site the sitemaps belong to
+func sitemapRoots(sitemaps []string) []string {
+	var roots []string
+	seen := make(map[string]bool)
+	for _, sitemap := range sitemaps {
+		u, err := url.Parse(sitemap)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		root := u.Scheme + "://" + u.Host + "/"
+		if !seen[root] {
+			seen[root] = true
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// stateFile returns the crawl state file in use, if any
+func stateFile() string {
+	if flagResume != "" {
+		return flagResume
+	}
+	return flagStateFile
+}
+
 func applyFlags(cmd *cobra.Command) {
 	if cmd.Flags().Changed("output-format") {
 		cfg.Set("output_format", types.OutputFormat(flagOutputFormat))
 	}
+	if cmd.Flags().Changed("group-by") {
+		cfg.Set("group_by", types.GroupBy(flagGroupBy))
+	}
+	if cmd.Flags().Changed("columns") {
+		cfg.Set("csv.columns", flagColumns)
+	}
+	if cmd.Flags().Changed("no-header") {
+		cfg.Set("csv.header", !flagNoHeader)
+	}
+	if cmd.Flags().Changed("template") {
+		cfg.Set("template", flagTemplate)
+		// A template implies the template format unless another is asked for
+		if !cmd.Flags().Changed("output-format") {
+			cfg.Set("output_format", types.FormatTemplate)
+		}
+	}
 	if cmd.Flags().Changed("output-file") {
 		cfg.Set("output_file", flagOutputFile)
 	}
@@ -169,16 +318,76 @@ func applyFlags(cmd *cobra.Command) {
 	if cmd.Flags().Changed("max-depth") {
 		cfg.Set("max_depth", flagMaxDepth)
 	}
+	if cmd.Flags().Changed("method") {
+		cfg.Set("request_method", types.RequestMethod(flagMethod))
+	}
+	if cmd.Flags().Changed("check-scope") {
+		cfg.Set("check_scope", types.CheckScope(flagCheckScope))
+	}
+	if cmd.Flags().Changed("rate-limit") {
+		cfg.Set("rate_limit.requests_per_second", flagRateLimit)
+	}
 	if cmd.Flags().Changed("verbose") {
 		cfg.Set("verbose", flagVerbose)
 	}
 	if cmd.Flags().Changed("no-progress") {
 		cfg.Set("show_progress", !flagNoProgress)
 	}
+	if cmd.Flags().Changed("sitemap") {
+		cfg.Set("sitemaps", flagSitemaps)
+	}
+	if cmd.Flags().Changed("no-sitemap") {
+		cfg.Set("use_sitemaps", !flagNoSitemap)
+	}
+	if cmd.Flags().Changed("include") {
+		cfg.Set("follow.include", flagInclude)
+	}
+	if cmd.Flags().Changed("exclude") {
+		cfg.Set("follow.exclude", flagExclude)
+	}
+	if cmd.Flags().Changed("subdomains") {
+		cfg.Set("follow.subdomains", flagSubdomains)
+	}
+	if cmd.Flags().Changed("ignore-nofollow") {
+		cfg.Set("follow.respect_nofollow", !flagNoNofollow)
+	}
+	if cmd.Flags().Changed("ignore-canonical") {
+		cfg.Set("follow.respect_canonical", !flagNoCanonical)
+	}
+	if cmd.Flags().Changed("max-query-variants") {
+		cfg.Set("follow.max_query_variants", flagMaxVariants)
+	}
+	if cmd.Flags().Changed("max-pages") {
+		cfg.Set("budget.max_pages", flagMaxPages)
+	}
+	if cmd.Flags().Changed("max-links") {
+		cfg.Set("budget.max_links", flagMaxLinks)
+	}
+	if cmd.Flags().Changed("max-duration") {
+		cfg.Set("budget.max_duration", flagMaxDuration)
+	}
+	if cmd.Flags().Changed("max-host-requests") {
+		cfg.Set("budget.max_host_requests", flagMaxHostReqs)
+	}
+	if cmd.Flags().Changed("max-bytes") {
+		cfg.Set("budget.max_bytes", flagMaxBytes)
+	}
+	if cmd.Flags().Changed("base-url") {
+		cfg.Set("directory.base_url", flagBaseURL)
+	}
+	if cmd.Flags().Changed("index-file") {
+		cfg.Set("directory.index_files", flagIndexFiles)
+	}
+	if cmd.Flags().Changed("no-clean-urls") {
+		cfg.Set("directory.clean_urls", !flagNoCleanURLs)
+	}
 }
 
 func runWithUI(c *checker.Checker, urls []string) (*types.CheckResult, error) {
-	model := ui.NewProgressModel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	model := ui.NewProgressModel().WithCancel(cancel)
 
 	// Set up progress callback
 	p := tea.NewProgram(model)
@@ -186,10 +395,13 @@ func runWithUI(c *checker.Checker, urls []string) (*types.CheckResult, error) {
 		p.Send(ui.ProgressMsg{URL: url, Status: status})
 	})
 
-	// Run checker in goroutine
+	// Run checker in goroutine. A check that fails without a result has
+	// nothing for the UI to show; its error is passed back instead.
+	checkErr := make(chan error, 1)
 	go func() {
-		result, err := c.CheckURLs(context.Background(), urls)
-		if err != nil {
+		result, err := c.CheckURLs(ctx, urls)
+		if err != nil && result == nil {
+			checkErr <- err
 			p.Quit()
 			return
 		}
@@ -207,7 +419,12 @@ func runWithUI(c *checker.Checker, urls []string) (*types.CheckResult, error) {
 		return m.Result(), nil
 	}
 
-	return nil, fmt.Errorf("no result available")
+	select {
+	case err := <-checkErr:
+		return nil, err
+	default:
+		return nil, fmt.Errorf("no result available")
+	}
 }
 
 func runWithoutUI(c *checker.Checker, urls []string) (*types.CheckResult, error) {
@@ -218,134 +435,67 @@ func runWithoutUI(c *checker.Checker, urls []string) (*types.CheckResult, error)
 		})
 	}
 
-	return c.CheckURLs(context.Background(), urls)
-}
-
-func outputResults(result *types.CheckResult) error {
-	formatter := output.GetFormatter(cfg.Get().OutputFormat)
+	// Stop on Ctrl+C but still report what was checked
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Determine output destination
-	w := os.Stdout
-	if cfg.Get().OutputFile != "" {
-		f, err := os.Create(cfg.Get().OutputFile)
-		if err != nil {
-			return fmt.Errorf("failed to create output file: %w", err)
-		}
-		defer f.Close()
-		w = f
+	result, err := c.CheckURLs(ctx, urls)
+	if err != nil && result != nil && result.Incomplete {
+		return result, nil
 	}
-
-	return formatter.Format(result, w)
+	return result, err
 }
 
-// executeCheck performs the actual link checking
-func executeCheck(args []string) error {
-	// Collect URLs to check
-	urls, err := collectURLs(args)
-	if err != nil {
-		return fmt.Errorf("failed to collect URLs: %w", err)
-	}
-
-	if len(urls) == 0 {
-		return fmt.Errorf("no URLs provided. Use --stdin to read from stdin or provide URLs as arguments")
-	}
-
-	// Create checker
-	c, err := checker.New(cfg.Get())
-	if err != nil {
-		return fmt.Errorf("failed to create checker: %w", err)
-	}
-
-	// Set up UI
-	var result *types.CheckResult
-	if cfg.Get().ShowProgress {
-		result, err = runWithUI(c, urls)
-	} else {
-		result, err = runWithoutUI(c, urls)
-	}
-
-	if err != nil {
-		return fmt.Errorf("check failed: %w", err)
-	}
-
-	// Format and output results
-	if err := outputResults(result); err != nil {
-		return fmt.Errorf("failed to output results: %w", err)
-	}
-
-	// Exit with error code if issues found
-	if result.TotalDead > 0 || result.TotalErrors > 0 {
-		os.Exit(1)
+// validateChoices reports the first setting whose value is not one of its
+// choices; unset ones fall back to their default
+func validateChoices(config *types.Config) error {
+	settings := []struct {
+		name    string
+		value   string
+		choices []string
+	}{
+		{"method", string(config.RequestMethod),
+			[]string{string(types.MethodHead), string(types.MethodGet), string(types.MethodRange)}},
+		{"check scope", string(config.CheckScope),
+			[]string{string(types.CheckScopeAll), string(types.CheckScopeInternal), string(types.CheckScopeExternal)}},
+		{"group by", string(config.GroupBy),
+			[]string{string(types.GroupByStatus), string(types.GroupByPage)}},
+	}
+	for _, s := range settings {
+		if s.value != "" && !slices.Contains(s.choices, s.value) {
+			return fmt.Errorf("unknown %s %q (available: %s)", s.name, s.value, strings.Join(s.choices, ", "))
+		}
 	}
-
 	return nil
 }
 
-// runCheckCommand handles the check subcommand
-func runCheckCommand(cmd *cobra.Command, args []string) error {
-	// Force single mode
-	cfg.Set("mode", types.ModeSingle)
-
-	// Apply flags
-	applyCheckFlags(cmd)
-
-	return executeCheck(args)
-}
-
-// runCrawlCommand handles the crawl subcommand
-func runCrawlCommand(cmd *cobra.Command, args []string) error {
-	// Force crawler mode
-	cfg.Set("mode", types.ModeCrawler)
-
-	// Apply flags
-	applyCrawlFlags(cmd)
-
-	return executeCheck(args)
+// newFormatter returns the formatter for the configured output format
+func newFormatter() (output.Formatter, error) {
+	if cfg.Get().OutputFormat == types.FormatTemplate {
+		return output.NewTemplateFormatter(cfg.Get().Template)
+	}
+	return output.GetFormatter(cfg.Get().OutputFormat, output.Options{
+		GroupBy:  cfg.Get().GroupBy,
+		Columns:  cfg.Get().CSV.Columns,
+		NoHeader: !cfg.Get().CSV.Header,
+	}), nil
 }
 
-// applyCheckFlags applies check command flags to config
-func applyCheckFlags(cmd *cobra.Command) {
-	if cmd.Flags().Changed("concurrency") {
-		cfg.Set("concurrency", flagConcurrency)
-	}
-	if cmd.Flags().Changed("timeout") {
-		cfg.Set("timeout", flagTimeout)
+// openOutput opens the configured output file, or stdout
+func openOutput() (*os.File, error) {
+	if cfg.Get().OutputFile == "" {
+		return os.Stdout, nil
 	}
-	if cmd.Flags().Changed("verbose") {
-		cfg.Set("verbose", flagVerbose)
-	}
-	if cmd.Flags().Changed("no-progress") {
-		cfg.Set("show_progress", !flagNoProgress)
-	}
-	if cmd.Flags().Changed("output-format") {
-		cfg.Set("output_format", types.OutputFormat(flagOutputFormat))
-	}
-	if cmd.Flags().Changed("output-file") {
-		cfg.Set("output_file", flagOutputFile)
+	f, err := os.Create(cfg.Get().OutputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
 	}
+	return f, nil
 }
 
-// applyCrawlFlags applies crawl command flags to config
-func applyCrawlFlags(cmd *cobra.Command) {
-	if cmd.Flags().Changed("max-depth") {
-		cfg.Set("max_depth", flagMaxDepth)
-	}
-	if cmd.Flags().Changed("concurrency") {
-		cfg.Set("concurrency", flagConcurrency)
-	}
-	if cmd.Flags().Changed("timeout") {
-		cfg.Set("timeout", flagTimeout)
-	}
-	if cmd.Flags().Changed("verbose") {
-		cfg.Set("verbose", flagVerbose)
-	}
-	if cmd.Flags().Changed("no-progress") {
-		cfg.Set("show_progress", !flagNoProgress)
-	}
-	if cmd.Flags().Changed("output-format") {
-		cfg.Set("output_format", types.OutputFormat(flagOutputFormat))
-	}
-	if cmd.Flags().Changed("output-file") {
-		cfg.Set("output_file", flagOutputFile)
+// closeOutput closes an output file opened by openOutput
+func closeOutput(w *os.File) {
+	if w != os.Stdout {
+		w.Close()
 	}
 }
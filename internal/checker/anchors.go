@@ -0,0 +1,131 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxAnchorPageSize caps how much of a page is read to index its anchors
+const maxAnchorPageSize = 5 * 1024 * 1024
+
+// anchorIndex caches the fragment targets (id and a[name] values) of pages.
+// Pages downloaded by the crawler are indexed as they arrive; any other page
+// is fetched once, the first time one of its fragments is checked.
+type anchorIndex struct {
+	checker *Checker
+	mu      sync.Mutex
+	pages   map[string]*anchorPage
+}
+
+// anchorPage holds the indexed anchors of a single page
+type anchorPage struct {
+	once    sync.Once
+	anchors map[string]bool // nil when the page could not be indexed
+}
+
+func newAnchorIndex(c *Checker) *anchorIndex {
+	return &anchorIndex{
+		checker: c,
+		pages:   make(map[string]*anchorPage),
+	}
+}
+
+// has reports whether the page at u defines the anchor named by u's fragment.
+// Pages that are not HTML or cannot be fetched are given the benefit of the doubt.
+func (a *anchorIndex) has(ctx context.Context, u *url.URL) bool {
+	fragment := u.Fragment
+	if fragment == "" || strings.EqualFold(fragment, "top") {
+		return true
+	}
+
+	page := a.page(pageKey(u))
+	page.once.Do(func() {
+		page.anchors = a.fetch(ctx, pageKey(u))
+	})
+	if page.anchors == nil {
+		return true
+	}
+
+	return page.anchors[fragment]
+}
+
+// store indexes a page body that was already downloaded, e.g. by the crawler
+func (a *anchorIndex) store(pageURL *url.URL, contentType string, body []byte) {
+	if !isHTML(contentType) {
+		return
+	}
+
+	page := a.page(pageKey(pageURL))
+	page.once.Do(func() {
+		page.anchors = parseAnchors(bytes.NewReader(body))
+	})
+}
+
+// page returns the cache entry for key, creating it on first use
+func (a *anchorIndex) page(key string) *anchorPage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	page, ok := a.pages[key]
+	if !ok {
+		page = &anchorPage{}
+		a.pages[key] = page
+	}
+	return page
+}
+
+// fetch downloads a page and indexes its anchors
+func (a *anchorIndex) fetch(ctx context.Context, pageURL string) map[string]bool {
+	resp, err := a.checker.doRequest(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || !isHTML(resp.Header.Get("Content-Type")) {
+		return nil
+	}
+	return parseAnchors(io.LimitReader(resp.Body, maxAnchorPageSize))
+}
+
+// parseAnchors collects every id attribute and every a[name] from an HTML document
+func parseAnchors(r io.Reader) map[string]bool {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil
+	}
+
+	anchors := make(map[string]bool)
+	doc.Find("[id]").Each(func(_ int, s *goquery.Selection) {
+		anchors[s.AttrOr("id", "")] = true
+	})
+	doc.Find("a[name]").Each(func(_ int, s *goquery.Selection) {
+		anchors[s.AttrOr("name", "")] = true
+	})
+	return anchors
+}
+
+// pageKey returns u without its fragment
+func pageKey(u *url.URL) string {
+	page := *u
+	page.Fragment = ""
+	page.RawFragment = ""
+	return page.String()
+}
+
+// isHTML reports whether a Content-Type header denotes an HTML document
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
@@ -0,0 +1,195 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// errHostBudget is returned for requests to a host whose request budget is spent
+var errHostBudget = errors.New("request budget for host exhausted")
+
+// budgetExceeded is the cancellation cause of a check stopped by a budget
+type budgetExceeded struct {
+	reason types.StopReason
+}
+
+func (e *budgetExceeded) Error() string {
+	return fmt.Sprintf("budget exceeded: %s", e.reason)
+}
+
+// budget enforces the configured limits on a check. Hitting the link,
+// duration or byte limit cancels the check; hitting the page limit stops
+// new pages from being crawled, and a host's request limit stops requests
+// to that host only.
+type budget struct {
+	config types.BudgetConfig
+
+	mu           sync.Mutex
+	cancel       context.CancelCauseFunc
+	reason       types.StopReason // first limit reached
+	pages        int
+	links        int
+	bytes        int64
+	hostRequests map[string]int
+}
+
+func newBudget(config types.BudgetConfig) *budget {
+	return &budget{
+		config:       config,
+		hostRequests: make(map[string]int),
+	}
+}
+
+// start derives the context a check runs under, which the budget cancels
+// when a hard limit is reached
+func (b *budget) start(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	b.mu.Lock()
+	b.cancel = cancel
+	b.mu.Unlock()
+
+	if b.config.MaxDuration <= 0 {
+		return ctx, func() { cancel(nil) }
+	}
+	limit := time.Duration(b.config.MaxDuration) * time.Second
+	timed, stop := context.WithTimeoutCause(ctx, limit, &budgetExceeded{types.StopMaxDuration})
+	return timed, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
+// stopReason returns the limit that cut the check short, if any
+func (b *budget) stopReason(ctx context.Context) types.StopReason {
+	var exceeded *budgetExceeded
+	if errors.As(context.Cause(ctx), &exceeded) {
+		return exceeded.reason
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.reason
+}
+
+// exceed records that a limit was reached and, for hard limits, cancels the check
+func (b *budget) exceed(reason types.StopReason, hard bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.reason == "" {
+		b.reason = reason
+	}
+	if hard && b.cancel != nil {
+		b.cancel(&budgetExceeded{reason})
+	}
+}
+
+// allowPage counts a page about to be crawled and reports whether it is
+// within max_pages
+func (b *budget) allowPage() bool {
+	if b.config.MaxPages <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	b.pages++
+	over := b.pages > b.config.MaxPages
+	b.mu.Unlock()
+
+	if over {
+		b.exceed(types.StopMaxPages, false)
+	}
+	return !over
+}
+
+// allowLink counts a link about to be checked and reports whether it is
+// within max_links
+func (b *budget) allowLink() bool {
+	if b.config.MaxLinks <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	b.links++
+	over := b.links > b.config.MaxLinks
+	b.mu.Unlock()
+
+	if over {
+		b.exceed(types.StopMaxLinks, true)
+	}
+	return !over
+}
+
+// allowRequest counts a request to host and reports whether it is within
+// max_host_requests
+func (b *budget) allowRequest(host string) bool {
+	if b.config.MaxHostRequests <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	b.hostRequests[host]++
+	over := b.hostRequests[host] > b.config.MaxHostRequests
+	b.mu.Unlock()
+
+	if over {
+		b.exceed(types.StopMaxHostRequests, false)
+	}
+	return !over
+}
+
+// addBytes counts downloaded response bytes against max_bytes
+func (b *budget) addBytes(n int) {
+	if b.config.MaxBytes <= 0 || n == 0 {
+		return
+	}
+	b.mu.Lock()
+	b.bytes += int64(n)
+	over := b.bytes > b.config.MaxBytes
+	b.mu.Unlock()
+
+	if over {
+		b.exceed(types.StopMaxBytes, true)
+	}
+}
+
+// Transport returns an http.RoundTripper that enforces the per-host request
+// and byte limits on requests made through next
+func (b *budget) Transport(next http.RoundTripper) http.RoundTripper {
+	return &budgetTransport{budget: b, next: next}
+}
+
+// budgetTransport is an http.RoundTripper that counts requests and response bytes
+type budgetTransport struct {
+	budget *budget
+	next   http.RoundTripper
+}
+
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Hostname())
+	if !t.budget.allowRequest(host) {
+		return nil, fmt.Errorf("%w: %s", errHostBudget, host)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || t.budget.config.MaxBytes <= 0 {
+		return resp, err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, budget: t.budget}
+	return resp, nil
+}
+
+// countingBody counts the bytes read from a response body against the budget
+type countingBody struct {
+	io.ReadCloser
+	budget *budget
+}
+
+func (r *countingBody) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.budget.addBytes(n)
+	return n, err
+}
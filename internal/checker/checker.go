@@ -2,10 +2,15 @@ package checker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,31 +22,70 @@ import (
 type Checker struct {
 	config      *types.Config
 	results     []types.LinkResult
-	visited     map[string]bool
+	visited     map[string]bool // normalized URLs already checked
 	mu          sync.Mutex
 	client      *http.Client
+	scheduler   *Scheduler
+	robots      *robotsCache
+	anchors     *anchorIndex
 	onProgress  func(url string, status types.LinkStatus)
+	onResult    func(result types.LinkResult)
 	ignoreRegex []*regexp.Regexp
+	normalizer  *normalizer
+	followScope *urlScope // pages a crawl visits
+	checkRules  *urlScope // links that are checked
+	budget      *budget
+	rules       []statusRule
+	internal    []string // domain globs treated as internal
+	journal     *journal
+	crawled     map[string]bool // normalized pages whose links have all been checked and queued
+	queued      map[string]bool // normalized pages queued for crawling
+	pending     []pendingPage   // frontier restored from a journal
+	sitemaps    *sitemapIndex
+	seeds       []string
+	site        *siteTransport // directory mode: serves the build directory
+	referrers   map[string][]types.Referrer
+	policies    map[string]pagePolicy // crawl signals of pages being scraped
+	followed    map[string]bool       // links a crawl policy allowed to be followed, by page key
+	notFollowed map[string]types.NoFollowReason
 }
 
 // New creates a new link checker
 func New(config *types.Config) (*Checker, error) {
 	c := &Checker{
-		config:  config,
-		results: make([]types.LinkResult, 0),
-		visited: make(map[string]bool),
-		client: &http.Client{
-			Timeout: time.Duration(config.Timeout) * time.Second,
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if !config.FollowRedirects {
-					return http.ErrUseLastResponse
-				}
-				return nil
-			},
-		},
+		config:      config,
+		results:     make([]types.LinkResult, 0),
+		visited:     make(map[string]bool),
+		crawled:     make(map[string]bool),
+		queued:      make(map[string]bool),
+		referrers:   make(map[string][]types.Referrer),
+		policies:    make(map[string]pagePolicy),
+		followed:    make(map[string]bool),
+		notFollowed: make(map[string]types.NoFollowReason),
+		sitemaps:    newSitemapIndex(),
+		client:      &http.Client{},
+		scheduler:   NewScheduler(config.RateLimit, maxRetryAfter(config.Retry)),
+		budget:      newBudget(config.Budget),
 		ignoreRegex: make([]*regexp.Regexp, 0),
 	}
 
+	// Route every request through the budget and the per-host scheduler,
+	// except those for a site being checked from disk. The timeout starts
+	// once the scheduler lets a request go.
+	c.client.CheckRedirect = c.checkRedirect
+	timeout := &timeoutTransport{timeout: time.Duration(config.Timeout) * time.Second, next: http.DefaultTransport}
+	c.client.Transport = c.budget.Transport(c.scheduler.Transport(timeout))
+	if config.Mode == types.ModeDirectory {
+		site, err := newSiteTransport(config.Directory, c.client.Transport)
+		if err != nil {
+			return nil, err
+		}
+		c.site = site
+		c.client.Transport = site
+	}
+	c.robots = newRobotsCache(c)
+	c.anchors = newAnchorIndex(c)
+
 	// Compile ignore patterns
 	for _, pattern := range config.IgnorePatterns {
 		re, err := regexp.Compile(pattern)
@@ -51,6 +95,19 @@ func New(config *types.Config) (*Checker, error) {
 		c.ignoreRegex = append(c.ignoreRegex, re)
 	}
 
+	rules, err := compileStatusRules(config.StatusRules)
+	if err != nil {
+		return nil, err
+	}
+	c.rules = rules
+
+	c.normalizer, err = newNormalizer(config.Normalize)
+	if err != nil {
+		return nil, err
+	}
+	c.followScope = newURLScope(config.Follow.ScopeRules)
+	c.checkRules = newURLScope(config.Check)
+
 	return c, nil
 }
 
@@ -59,84 +116,188 @@ func (c *Checker) SetProgressCallback(fn func(url string, status types.LinkStatu
 	c.onProgress = fn
 }
 
-// CheckURLs checks a list of URLs based on the configured mode
-func (c *Checker) CheckURLs(ctx context.Context, urls []string) (*types.CheckResult, error) {
+// SetResultCallback sets a callback that receives every link result as soon
+// as it is recorded. It is called concurrently; results carry the referrers
+// found up to that point.
+func (c *Checker) SetResultCallback(fn func(result types.LinkResult)) {
+	c.onResult = fn
+}
+
+// CheckURLs checks a list of URLs based on the configured mode. When ctx is
+// cancelled it stops in-flight requests and returns the links checked so far
+// in a result flagged as incomplete, together with ctx's error. A check cut
+// short by its budget is flagged the same way, without an error.
+func (c *Checker) CheckURLs(parent context.Context, urls []string) (*types.CheckResult, error) {
 	startTime := time.Now()
+	ctx, cancel := c.budget.start(parent)
+	defer cancel()
 
-	for _, u := range urls {
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
+	c.seeds = urls
+	c.setInternalDomains(urls)
+
+	var err error
+	switch {
+	case c.config.Mode == types.ModeFiles:
+		// urls are source files and directories in files mode
+		err = c.checkFiles(ctx, urls)
+	case c.crawling():
+		for _, u := range urls {
+			if err = ctx.Err(); err != nil {
+				break
+			}
+			if err = c.crawlAndCheck(ctx, u); err != nil {
+				break
+			}
 		}
+	default:
+		links := make([]foundLink, len(urls))
+		for i, u := range urls {
+			links[i] = foundLink{url: u}
+		}
+		err = c.checkConcurrently(ctx, links)
+	}
+
+	if err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+
+	result := c.buildResult(startTime, time.Now())
+	if parent.Err() != nil {
+		result.Incomplete = true
+		result.StopReason = types.StopCancelled
+		return result, parent.Err()
+	}
+	if reason := c.budget.stopReason(ctx); reason != "" {
+		result.Incomplete = true
+		result.StopReason = reason
+	}
+	return result, nil
+}
+
+// checkConcurrently checks links using a worker pool bounded by the configured concurrency
+func (c *Checker) checkConcurrently(ctx context.Context, links []foundLink) error {
+	workers := c.config.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(links) {
+		workers = len(links)
+	}
 
-		if c.config.Mode == types.ModeCrawler {
-			if err := c.crawlAndCheck(ctx, u); err != nil {
-				return nil, err
+	jobs := make(chan foundLink)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range jobs {
+				if link.file != "" || link.skip != "" {
+					c.checkLocalFile(link)
+				} else {
+					c.checkSingleURL(ctx, link.url, link.src)
+				}
 			}
-		} else {
-			c.checkSingleURL(u, "")
+		}()
+	}
+
+	// Stop handing out work as soon as the context is cancelled; workers
+	// finish the URL they are on and then exit
+feed:
+	for _, link := range links {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- link:
 		}
 	}
+	close(jobs)
+	wg.Wait()
 
-	endTime := time.Now()
-	return c.buildResult(startTime, endTime), nil
+	return ctx.Err()
 }
 
-// checkSingleURL checks a single URL
-func (c *Checker) checkSingleURL(targetURL, foundOn string) types.LinkResult {
+// checkSingleURL checks a single URL. A check interrupted by ctx is not recorded.
+func (c *Checker) checkSingleURL(ctx context.Context, targetURL string, src linkSource) types.LinkResult {
+	// Variants of a URL are checked once, as the first one found
+	key := c.normalize(targetURL)
+	c.addReferrer(key, src)
+
 	c.mu.Lock()
-	if c.visited[targetURL] {
+	if c.visited[key] {
 		c.mu.Unlock()
 		return types.LinkResult{URL: targetURL, Status: types.StatusSkipped}
 	}
-	c.visited[targetURL] = true
+	c.visited[key] = true
 	c.mu.Unlock()
 
-	// Check if URL should be ignored
-	if c.shouldIgnore(targetURL) {
-		result := types.LinkResult{
-			URL:       targetURL,
-			Status:    types.StatusSkipped,
-			FoundOn:   foundOn,
-			CheckedAt: time.Now(),
-		}
+	result := types.LinkResult{
+		URL:       targetURL,
+		FoundOn:   src.foundOn,
+		Element:   src.element,
+		Attribute: src.attribute,
+		Location:  src.location,
+	}
+
+	// Check if URL should be ignored or is outside the checked scope
+	if c.shouldIgnore(targetURL) || !c.inCheckScope(targetURL) {
+		result.Status = types.StatusSkipped
+		result.CheckedAt = time.Now()
 		c.addResult(result)
 		return result
 	}
 
-	startTime := time.Now()
+	// Past max_links the check is cancelled; this link is left unchecked
+	if !c.budget.allowLink() {
+		result.Status = types.StatusSkipped
+		return result
+	}
 
-	req, err := http.NewRequest("HEAD", targetURL, nil)
-	if err != nil {
-		result := types.LinkResult{
-			URL:       targetURL,
-			Status:    types.StatusError,
-			Error:     err.Error(),
-			FoundOn:   foundOn,
-			CheckedAt: time.Now(),
-		}
+	if !c.robotsAllowed(ctx, targetURL) {
+		result.Status = types.StatusBlockedByRobots
+		result.CheckedAt = time.Now()
 		c.addResult(result)
-		c.notifyProgress(targetURL, types.StatusError)
+		c.notifyProgress(targetURL, types.StatusBlockedByRobots)
 		return result
 	}
 
-	req.Header.Set("User-Agent", c.config.UserAgent)
+	probed := c.probeWithRetry(ctx, targetURL)
+	resp, err := probed.resp, probed.err
+	if ctx.Err() != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		result.Status = types.StatusSkipped
+		return result
+	}
 
-	resp, err := c.client.Do(req)
-	responseTime := time.Since(startTime)
+	result.ResponseTime = probed.responseTime
+	result.Method = probed.method
+	result.Attempts = probed.attempts
+	c.recordRedirects(&result, resp)
 
 	if err != nil {
 		status := types.StatusError
+		message := err.Error()
 		if err, ok := err.(net.Error); ok && err.Timeout() {
 			status = types.StatusTimeout
 		}
-		result := types.LinkResult{
-			URL:          targetURL,
-			Status:       status,
-			Error:        err.Error(),
-			FoundOn:      foundOn,
-			ResponseTime: responseTime,
-			CheckedAt:    time.Now(),
+		switch {
+		case errors.Is(err, errRedirectLoop):
+			status = types.StatusRedirectLoop
+		case errors.Is(err, errTooManyRedirects):
+			status = types.StatusTooManyRedirect
+		case errors.Is(err, errHostBudget):
+			status = types.StatusSkipped
+			if u, perr := url.Parse(targetURL); perr == nil {
+				message = fmt.Sprintf("not checked: budget.max_host_requests (%d) reached for %s",
+					c.config.Budget.MaxHostRequests, u.Hostname())
+			}
 		}
+		// A broken chain has no destination the link could be updated to
+		result.RedirectType = ""
+		result.Status = status
+		result.Error = message
+		result.CheckedAt = time.Now()
 		c.addResult(result)
 		c.notifyProgress(targetURL, status)
 		return result
@@ -144,22 +305,25 @@ func (c *Checker) checkSingleURL(targetURL, foundOn string) types.LinkResult {
 	defer resp.Body.Close()
 
 	// Determine status
-	status := c.determineStatus(resp.StatusCode)
-
-	result := types.LinkResult{
-		URL:           targetURL,
-		Status:        status,
-		StatusCode:    resp.StatusCode,
-		FoundOn:       foundOn,
-		ResponseTime:  responseTime,
-		CheckedAt:     time.Now(),
-		ContentType:   resp.Header.Get("Content-Type"),
-		ContentLength: resp.ContentLength,
+	status, rule := c.determineStatus(targetURL, resp.StatusCode)
+
+	result.Status = status
+	result.Rule = rule
+	result.StatusCode = resp.StatusCode
+	result.CheckedAt = time.Now()
+	result.ContentType = resp.Header.Get("Content-Type")
+	result.ContentLength = resp.ContentLength
+	if missing := resp.Header.Get(missingFileHeader); missing != "" && status == types.StatusDead {
+		result.Error = "missing file " + missing
 	}
 
-	// Handle redirects
-	if status == types.StatusRedirect && resp.Header.Get("Location") != "" {
-		result.RedirectURL = resp.Header.Get("Location")
+	// Verify the #fragment exists on the target page
+	if status == types.StatusOK && c.config.CheckFragments {
+		if fragment, ok := c.missingFragment(ctx, targetURL); ok {
+			status = types.StatusMissingFragment
+			result.Status = status
+			result.Error = fmt.Sprintf("fragment #%s not found", fragment)
+		}
 	}
 
 	c.addResult(result)
@@ -168,14 +332,115 @@ func (c *Checker) checkSingleURL(targetURL, foundOn string) types.LinkResult {
 	return result
 }
 
+// probe requests targetURL using the configured method strategy. It returns
+// the response that decides the link's status and the method that produced it.
+func (c *Checker) probe(ctx context.Context, targetURL string) (*http.Response, string, error) {
+	switch c.config.RequestMethod {
+	case types.MethodGet:
+		resp, err := c.doRequest(ctx, http.MethodGet, targetURL, nil)
+		return resp, http.MethodGet, err
+
+	case types.MethodRange:
+		size := c.config.RangeBytes
+		if size < 1 {
+			size = 1
+		}
+		headers := map[string]string{"Range": fmt.Sprintf("bytes=0-%d", size-1)}
+		resp, err := c.doRequest(ctx, http.MethodGet, targetURL, headers)
+		if err != nil {
+			return resp, http.MethodGet, err
+		}
+		// An empty resource has no first byte to send, so the range cannot
+		// be satisfied; ask for it as a whole instead
+		if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			resp.Body.Close()
+			resp, err = c.doRequest(ctx, http.MethodGet, targetURL, nil)
+			if err != nil {
+				return resp, http.MethodGet, err
+			}
+		}
+		// Servers that ignore Range would send the whole body, so never
+		// read more than we asked for
+		io.Copy(io.Discard, io.LimitReader(resp.Body, int64(size)))
+		return resp, http.MethodGet, nil
+
+	default:
+		resp, err := c.doRequest(ctx, http.MethodHead, targetURL, nil)
+		if err != nil || !c.shouldFallbackToGet(resp.StatusCode) {
+			return resp, http.MethodHead, err
+		}
+		resp.Body.Close()
+
+		resp, err = c.doRequest(ctx, http.MethodGet, targetURL, nil)
+		return resp, http.MethodGet, err
+	}
+}
+
+// recordRedirects copies the redirect chain behind resp onto result
+func (c *Checker) recordRedirects(result *types.LinkResult, resp *http.Response) {
+	hops := redirectChain(resp)
+	if len(hops) == 0 {
+		return
+	}
+
+	result.Redirects = hops
+	result.RedirectType = redirectType(hops[0].StatusCode)
+	result.RedirectURL = redirectTarget(resp)
+}
+
+// missingFragment reports the fragment of targetURL when the target page
+// has no element with that id or name
+func (c *Checker) missingFragment(ctx context.Context, targetURL string) (string, bool) {
+	u, err := url.Parse(targetURL)
+	if err != nil || u.Fragment == "" {
+		return "", false
+	}
+	if c.anchors.has(ctx, u) {
+		return "", false
+	}
+	return u.Fragment, true
+}
+
+// doRequest sends a single request with the configured user agent
+func (c *Checker) doRequest(ctx context.Context, method, targetURL string, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, targetURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", c.config.UserAgent)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	return c.client.Do(req)
+}
+
+// shouldFallbackToGet reports whether a HEAD response code should be retried with GET
+func (c *Checker) shouldFallbackToGet(code int) bool {
+	for _, fallback := range c.config.GetFallbackCodes {
+		if code == fallback {
+			return true
+		}
+	}
+	return false
+}
+
 // crawlAndCheck crawls a URL and checks all discovered links
 func (c *Checker) crawlAndCheck(ctx context.Context, startURL string) error {
 	collector := colly.NewCollector(
 		colly.MaxDepth(c.config.MaxDepth),
 		colly.Async(true),
 		colly.UserAgent(c.config.UserAgent),
+		colly.StdlibContext(ctx),
 	)
 
+	// Share the per-host scheduler with single-URL checks. Its transport
+	// applies the timeout after any rate limit wait, so colly's own client
+	// timeout, which would include the wait, is turned off.
+	collector.WithTransport(c.client.Transport)
+	collector.SetRequestTimeout(0)
+
 	// Set allowed domains if specified
 	if len(c.config.AllowedDomains) > 0 {
 		collector.AllowedDomains = c.config.AllowedDomains
@@ -187,24 +452,45 @@ func (c *Checker) crawlAndCheck(ctx context.Context, startURL string) error {
 		Parallelism: c.config.Concurrency,
 	})
 
-	// Extract and check all links
-	collector.OnHTML("a[href]", func(e *colly.HTMLElement) {
-		link := e.Request.AbsoluteURL(e.Attr("href"))
-		if link == "" {
-			return
-		}
-
-		// Check the link
-		c.checkSingleURL(link, e.Request.URL.String())
+	// Index the anchors of every crawled page so fragment checks don't refetch it
+	if c.config.CheckFragments {
+		collector.OnResponse(func(r *colly.Response) {
+			c.anchors.store(r.Request.URL, r.Headers.Get("Content-Type"), r.Body)
+		})
+	}
 
-		// Visit the link if in crawler mode (to find more links)
-		if c.config.Mode == types.ModeCrawler {
-			e.Request.Visit(link)
+	// Stop crawling new pages once max_pages is reached; links on pages
+	// already fetched are still checked
+	collector.OnRequest(func(r *colly.Request) {
+		if !c.budget.allowPage() {
+			r.Abort()
 		}
 	})
 
-	// Handle errors
+	// Read each page's nofollow and canonical signals, then extract and
+	// check links from every configured element and attribute
+	c.registerPolicies(ctx, collector)
+	c.registerExtractors(ctx, collector)
+
+	// Handle errors; requests aborted by cancellation are not failures
 	collector.OnError(func(r *colly.Response, err error) {
+		if ctx.Err() != nil {
+			return
+		}
+		page := r.Request.URL.String()
+		c.markCrawled(page)
+		c.journal.crawled(page, false)
+
+		// Linked pages already have a result from their link check; only
+		// crawl roots are reported here
+		c.mu.Lock()
+		checked := c.visited[c.normalize(page)]
+		c.visited[c.normalize(page)] = true
+		c.mu.Unlock()
+		if checked {
+			return
+		}
+
 		result := types.LinkResult{
 			URL:        r.Request.URL.String(),
 			Status:     types.StatusError,
@@ -216,9 +502,47 @@ func (c *Checker) crawlAndCheck(ctx context.Context, startURL string) error {
 		c.notifyProgress(r.Request.URL.String(), types.StatusError)
 	})
 
-	// Start crawling
-	if err := collector.Visit(startURL); err != nil {
-		return fmt.Errorf("failed to start crawling: %w", err)
+	// Remember finished pages so a resumed crawl does not revisit them. A page
+	// scraped while cancelling may not have had all its links queued.
+	collector.OnScraped(func(r *colly.Response) {
+		if ctx.Err() != nil {
+			return
+		}
+		page := r.Request.URL.String()
+		reached := r.StatusCode >= 200 && r.StatusCode < 300 && isHTML(r.Headers.Get("Content-Type"))
+		if reached {
+			c.sitemaps.markReached(c.pageKey(page))
+		}
+		c.markCrawled(page)
+		c.journal.crawled(page, reached)
+	})
+
+	// Start crawling, picking up any frontier left by an interrupted crawl
+	c.visitPending(collector)
+	if c.isCrawled(startURL) {
+		collector.Wait()
+		return nil
+	}
+	if !c.robotsAllowed(ctx, startURL) {
+		c.checkSingleURL(ctx, startURL, linkSource{})
+		collector.Wait()
+		return nil
+	}
+	// The seed may already be queued from the restored frontier
+	if c.claimPage(startURL) {
+		if err := collector.Visit(startURL); err == nil {
+			c.journal.queued(startURL, 1)
+		} else {
+			return fmt.Errorf("failed to start crawling: %w", err)
+		}
+	}
+
+	// Pages listed in the site's sitemaps are crawl roots too, as is every
+	// page of a build directory
+	c.seedFromSitemaps(ctx, collector, startURL)
+	if err := c.seedFromDirectory(collector); err != nil {
+		collector.Wait()
+		return err
 	}
 
 	// Wait for all async requests to complete
@@ -227,6 +551,125 @@ func (c *Checker) crawlAndCheck(ctx context.Context, startURL string) error {
 	return nil
 }
 
+// visitPending queues the frontier restored from a journal, at its original depth
+func (c *Checker) visitPending(collector *colly.Collector) {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	for _, page := range pending {
+		c.claimPage(page.url)
+		pageCtx := colly.NewContext()
+		pageCtx.Put(depthOffsetKey, page.depth-1)
+		collector.Request(http.MethodGet, page.url, nil, pageCtx, nil)
+	}
+}
+
+// crawling reports whether pages are crawled for links rather than only checked
+func (c *Checker) crawling() bool {
+	return c.config.Mode == types.ModeCrawler || c.config.Mode == types.ModeDirectory
+}
+
+// markCrawled records that all links on page have been checked and queued
+func (c *Checker) markCrawled(page string) {
+	key := c.pageKey(page)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.crawled[key] = true
+}
+
+// isCrawled reports whether page, or a variant of it, was already crawled,
+// possibly in a previous run
+func (c *Checker) isCrawled(page string) bool {
+	key := c.pageKey(page)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.crawled[key]
+}
+
+// claimPage records that page is about to be queued for crawling. It
+// reports false when a variant of page was already queued or crawled, as
+// colly only tells apart the exact URLs it visits.
+func (c *Checker) claimPage(page string) bool {
+	key := c.pageKey(page)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.queued[key] || c.crawled[key] {
+		return false
+	}
+	c.queued[key] = true
+	return true
+}
+
+// robotsAllowed reports whether robots.txt permits fetching targetURL.
+// It always returns true when respect_robots_txt is off.
+func (c *Checker) robotsAllowed(ctx context.Context, targetURL string) bool {
+	if !c.config.RespectRobotsTxt {
+		return true
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return true
+	}
+	return c.robots.allowed(ctx, u)
+}
+
+// setInternalDomains decides which hosts count as internal: the configured
+// internal_domains, or else, when crawling, the hosts of the seed URLs. URLs
+// checked on their own are not part of a site, so none of them is internal.
+func (c *Checker) setInternalDomains(seeds []string) {
+	if len(c.config.InternalDomains) > 0 || !c.crawling() {
+		c.internal = c.config.InternalDomains
+		return
+	}
+
+	c.internal = make([]string, 0, len(seeds))
+	for _, seed := range seeds {
+		if u, err := url.Parse(seed); err == nil && u.Hostname() != "" {
+			c.internal = append(c.internal, strings.ToLower(u.Hostname()))
+		}
+	}
+}
+
+// classify reports whether targetURL is internal or external
+func (c *Checker) classify(targetURL string) types.LinkScope {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return types.ScopeExternal
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range c.internal {
+		if matchDomain(domain, host) {
+			return types.ScopeInternal
+		}
+	}
+	return types.ScopeExternal
+}
+
+// inCheckScope reports whether targetURL should be checked under the
+// configured check scope and check rules
+func (c *Checker) inCheckScope(targetURL string) bool {
+	if !c.checkRules.allows(targetURL) {
+		return false
+	}
+
+	scope := c.config.CheckScope
+	if c.config.CheckExternalOnly && (scope == "" || scope == types.CheckScopeAll) {
+		scope = types.CheckScopeExternal
+	}
+
+	switch scope {
+	case types.CheckScopeInternal:
+		return c.classify(targetURL) == types.ScopeInternal
+	case types.CheckScopeExternal:
+		return c.classify(targetURL) == types.ScopeExternal
+	default:
+		return true
+	}
+}
+
 // shouldIgnore checks if a URL should be ignored based on patterns
 func (c *Checker) shouldIgnore(targetURL string) bool {
 	for _, re := range c.ignoreRegex {
@@ -237,23 +680,87 @@ func (c *Checker) shouldIgnore(targetURL string) bool {
 	return false
 }
 
-// determineStatus determines the link status based on HTTP status code
-func (c *Checker) determineStatus(code int) types.LinkStatus {
+// determineStatus determines the link status from the first status rule that
+// matches, falling back to the HTTP status code class. It also returns the
+// name of the matching rule, if any.
+func (c *Checker) determineStatus(targetURL string, code int) (types.LinkStatus, string) {
+	for i := range c.rules {
+		if c.rules[i].matches(targetURL, code) {
+			return c.rules[i].status, c.rules[i].name
+		}
+	}
+
 	switch {
 	case code >= 200 && code < 300:
-		return types.StatusOK
+		return types.StatusOK, ""
 	case code >= 300 && code < 400:
-		return types.StatusRedirect
+		return types.StatusRedirect, ""
 	default:
-		return types.StatusDead
+		return types.StatusDead, ""
 	}
 }
 
-// addResult adds a result to the results list (thread-safe)
+// addReferrer records that targetURL was found at src. Every occurrence is
+// recorded, including those of links that were already checked.
+func (c *Checker) addReferrer(targetURL string, src linkSource) {
+	if src.foundOn == "" {
+		return
+	}
+
+	ref := types.Referrer{
+		Page:      src.foundOn,
+		Text:      src.text,
+		Element:   src.element,
+		Attribute: src.attribute,
+		Location:  src.location,
+	}
+	c.mu.Lock()
+	added := c.appendReferrer(targetURL, ref)
+	c.mu.Unlock()
+
+	if added {
+		c.journal.referrer(targetURL, ref)
+	}
+}
+
+// appendReferrer records ref for targetURL unless the same link, on the same
+// page, element, attribute and location and with the same text, was already
+// recorded, as when a page interrupted mid-scrape is scraped again on resume.
+// It reports whether ref was added. The caller must hold c.mu.
+func (c *Checker) appendReferrer(targetURL string, ref types.Referrer) bool {
+	for _, seen := range c.referrers[targetURL] {
+		if seen.Page == ref.Page && seen.Element == ref.Element && seen.Attribute == ref.Attribute &&
+			seen.Text == ref.Text && sameLocation(seen.Location, ref.Location) {
+			return false
+		}
+	}
+	c.referrers[targetURL] = append(c.referrers[targetURL], ref)
+	return true
+}
+
+func sameLocation(a, b *types.SourceLocation) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// addResult classifies a result and adds it to the results list (thread-safe)
 func (c *Checker) addResult(result types.LinkResult) {
+	if result.Scope == "" {
+		result.Scope = c.classify(result.URL)
+	}
+
 	c.mu.Lock()
-	defer c.mu.Unlock()
 	c.results = append(c.results, result)
+	live := result
+	live.Referrers = slices.Clone(c.referrers[c.normalize(result.URL)])
+	c.mu.Unlock()
+
+	c.journal.result(result)
+	if c.onResult != nil {
+		c.onResult(live)
+	}
 }
 
 // notifyProgress notifies the progress callback if set
@@ -269,25 +776,45 @@ func (c *Checker) buildResult(startTime, endTime time.Time) *types.CheckResult {
 	defer c.mu.Unlock()
 
 	result := &types.CheckResult{
-		StartTime:    startTime,
-		EndTime:      endTime,
-		Duration:     endTime.Sub(startTime),
-		TotalChecked: len(c.results),
-		Links:        c.results,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Duration:  endTime.Sub(startTime),
+		Links:     c.results,
 	}
 
 	// Calculate statistics
-	for _, link := range c.results {
-		switch link.Status {
-		case types.StatusOK:
-			result.TotalOK++
-		case types.StatusDead:
-			result.TotalDead++
-		case types.StatusRedirect:
-			result.TotalRedirect++
-		case types.StatusError, types.StatusTimeout:
-			result.TotalErrors++
+	var totals types.LinkTotals
+	for i, link := range c.results {
+		key := c.normalize(link.URL)
+		c.results[i].Referrers = c.referrers[key]
+		c.results[i].NotFollowed = c.notFollowed[c.pageKey(link.URL)]
+
+		totals.Add(link)
+		switch link.Scope {
+		case types.ScopeInternal:
+			result.Internal.Add(link)
+		case types.ScopeExternal:
+			result.External.Add(link)
+		}
+		if link.RedirectType == types.RedirectPermanent {
+			result.TotalMoved++
+		}
+	}
+	result.TotalChecked = len(c.results)
+	result.TotalOK = totals.OK
+	result.TotalDead = totals.Dead
+	result.TotalRedirect = totals.Redirect
+	result.TotalErrors = totals.Errors
+	result.TotalBlocked = totals.Blocked
+	result.TotalLimited = totals.Limited
+	result.TotalSkipped = totals.Skipped
+
+	if c.crawling() {
+		seeds := make([]string, len(c.seeds))
+		for i, seed := range c.seeds {
+			seeds[i] = c.normalize(seed)
 		}
+		result.Sitemap = c.sitemaps.report(seeds)
 	}
 
 	return result
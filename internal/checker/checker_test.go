@@ -0,0 +1,1985 @@
+package checker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+func newTestConfig() *types.Config {
+	config := types.DefaultConfig()
+	config.Timeout = 5
+	config.RespectRobotsTxt = false
+	return config
+}
+
+func TestCheckURLsConcurrent(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		inFlight int
+		maxSeen  int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxSeen {
+			maxSeen = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := newTestConfig()
+	config.Concurrency = 4
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	urls := make([]string, 12)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("%s/page/%d", server.URL, i)
+	}
+
+	result, err := c.CheckURLs(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	if result.TotalChecked != len(urls) {
+		t.Errorf("Expected %d results, got %d", len(urls), result.TotalChecked)
+	}
+	if result.TotalOK != len(urls) {
+		t.Errorf("Expected %d OK links, got %d", len(urls), result.TotalOK)
+	}
+	if maxSeen < 2 {
+		t.Errorf("Expected concurrent requests, max in flight was %d", maxSeen)
+	}
+	if maxSeen > config.Concurrency {
+		t.Errorf("Expected at most %d requests in flight, got %d", config.Concurrency, maxSeen)
+	}
+}
+
+func TestCheckURLsCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c, err := New(newTestConfig())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := c.CheckURLs(ctx, []string{server.URL})
+	if err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if result == nil || !result.Incomplete || result.StopReason != types.StopCancelled {
+		t.Errorf("Expected a partial result flagged incomplete, got %+v", result)
+	}
+}
+
+func TestResultCallback(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/a">A</a> <a href="/b">B</a> <a href="/gone">Gone</a>`)
+	})
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := newTestConfig()
+	config.Mode = types.ModeCrawler
+	config.MaxDepth = 1
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]types.LinkResult)
+	)
+	c.SetResultCallback(func(result types.LinkResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := seen[result.URL]; ok {
+			t.Errorf("Result for %s reported twice", result.URL)
+		}
+		seen[result.URL] = result
+	})
+
+	result, err := c.CheckURLs(context.Background(), []string{server.URL + "/"})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	if len(seen) != len(result.Links) {
+		t.Errorf("Expected %d results reported, got %d", len(result.Links), len(seen))
+	}
+	for _, link := range result.Links {
+		live, ok := seen[link.URL]
+		if !ok {
+			t.Errorf("Result for %s was not reported", link.URL)
+			continue
+		}
+		if live.Status != link.Status || live.Scope != link.Scope {
+			t.Errorf("Reported %s as %s/%s, final result is %s/%s", link.URL, live.Status, live.Scope, link.Status, link.Scope)
+		}
+	}
+	if gone := seen[server.URL+"/gone"]; len(gone.Referrers) != 1 || gone.Referrers[0].Page != server.URL+"/" {
+		t.Errorf("Expected /gone reported with its referrer, got %+v", gone.Referrers)
+	}
+}
+
+func TestCancelStopsCrawl(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/fast">fast</a> <a href="/slow">slow</a>`)
+	})
+	mux.HandleFunc("/fast", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := newTestConfig()
+	config.Mode = types.ModeCrawler
+	config.Timeout = 30
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result, err := c.CheckURLs(ctx, []string{server.URL + "/"})
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancellation to abort in-flight requests, took %v", elapsed)
+	}
+	if result == nil || !result.Incomplete {
+		t.Fatalf("Expected a partial result flagged incomplete, got %+v", result)
+	}
+
+	for _, link := range result.Links {
+		if link.URL == server.URL+"/slow" {
+			t.Errorf("Expected the interrupted check not to be recorded, got %s", link.Status)
+		}
+	}
+}
+
+func TestHeadFallbackToGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		fallbackCodes  []int
+		expectedStatus types.LinkStatus
+		expectedMethod string
+	}{
+		{"fallback enabled", []int{405}, types.StatusOK, http.MethodGet},
+		{"fallback disabled", nil, types.StatusDead, http.MethodHead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestConfig()
+			config.GetFallbackCodes = tt.fallbackCodes
+
+			c, err := New(config)
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+
+			result := c.checkSingleURL(context.Background(), server.URL, linkSource{})
+			if result.Status != tt.expectedStatus {
+				t.Errorf("Expected status %s, got %s", tt.expectedStatus, result.Status)
+			}
+			if result.Method != tt.expectedMethod {
+				t.Errorf("Expected method %s, got %s", tt.expectedMethod, result.Method)
+			}
+		})
+	}
+}
+
+func TestRangeRequest(t *testing.T) {
+	var gotRange string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRange = r.Header.Get("Range")
+		w.WriteHeader(http.StatusPartialContent)
+	}))
+	defer server.Close()
+
+	config := newTestConfig()
+	config.RequestMethod = types.MethodRange
+	config.RangeBytes = 16
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result := c.checkSingleURL(context.Background(), server.URL, linkSource{})
+	if result.Status != types.StatusOK {
+		t.Errorf("Expected status %s, got %s", types.StatusOK, result.Status)
+	}
+	if gotRange != "bytes=0-15" {
+		t.Errorf("Expected Range header bytes=0-15, got %q", gotRange)
+	}
+}
+
+func TestRangeRequestEmptyResource(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Header.Get("Range"))
+		// A zero-length resource has no byte range to serve
+		if r.Header.Get("Range") != "" {
+			w.Header().Set("Content-Range", "bytes */0")
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Header().Set("Content-Length", "0")
+	}))
+	defer server.Close()
+
+	config := newTestConfig()
+	config.RequestMethod = types.MethodRange
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result := c.checkSingleURL(context.Background(), server.URL, linkSource{})
+	if result.Status != types.StatusOK {
+		t.Errorf("Expected status %s, got %s (%s)", types.StatusOK, result.Status, result.Error)
+	}
+	if len(requests) != 2 || requests[1] != "" {
+		t.Errorf("Expected a range request and a plain GET, got ranges %q", requests)
+	}
+}
+
+func TestRetryOnStatus(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+
+		if n < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := newTestConfig()
+	config.Retry.MaxAttempts = 3
+	config.Retry.InitialBackoff = 1
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result := c.checkSingleURL(context.Background(), server.URL, linkSource{})
+	if result.Status != types.StatusOK {
+		t.Errorf("Expected status %s, got %s", types.StatusOK, result.Status)
+	}
+	if result.Attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", result.Attempts)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"seconds", "120", 2 * time.Minute, true},
+		{"http date", "Mon, 15 Jan 2024 10:30:30 GMT", 30 * time.Second, true},
+		{"date in the past", "Mon, 15 Jan 2024 10:00:00 GMT", 0, true},
+		{"empty", "", 0, false},
+		{"garbage", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, ok := parseRetryAfter(tt.value, now)
+			if ok != tt.ok || wait != tt.expected {
+				t.Errorf("parseRetryAfter(%q) = %v, %v; expected %v, %v", tt.value, wait, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSchedulerPacesPerHost(t *testing.T) {
+	s := NewScheduler(types.RateLimitConfig{
+		RequestsPerSecond: 100,
+		Hosts: []types.HostRateLimit{
+			{Domain: "*.slow.test", RequestsPerSecond: 20},
+		},
+	}, 0)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := s.Acquire(context.Background(), "docs.slow.test"); err != nil {
+			t.Fatalf("Acquire() error: %v", err)
+		}
+		s.free("docs.slow.test")
+	}
+
+	// Five requests at 20/s need at least four 50ms gaps
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected host override to pace requests, took only %s", elapsed)
+	}
+}
+
+func TestSchedulerSlowsDownOn429(t *testing.T) {
+	s := NewScheduler(types.RateLimitConfig{Adaptive: true}, time.Second)
+
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	s.adapt("example.com", resp)
+
+	if interval := s.state("example.com").interval; interval != adaptiveStartInterval {
+		t.Errorf("Expected interval %s after 429, got %s", adaptiveStartInterval, interval)
+	}
+
+	s.adapt("example.com", resp)
+	if interval := s.state("example.com").interval; interval != 2*adaptiveStartInterval {
+		t.Errorf("Expected interval to double after second 429, got %s", interval)
+	}
+
+	// A Retry-After beyond retry.max_retry_after holds the host only that long
+	resp.Header.Set("Retry-After", "3600")
+	s.adapt("other.example", resp)
+	if next := s.state("other.example").next; time.Until(next) > time.Second {
+		t.Errorf("Expected Retry-After capped at 1s, host held for %s", time.Until(next))
+	}
+}
+
+func TestRateLimitWaitIsNotTimedOut(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// The third request waits two seconds for its turn, past the 1s timeout
+	config := newTestConfig()
+	config.Timeout = 1
+	config.RateLimit.RequestsPerSecond = 1
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	urls := []string{server.URL + "/a", server.URL + "/b", server.URL + "/c"}
+	result, err := c.CheckURLs(context.Background(), urls)
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+	for _, link := range result.Links {
+		if link.Status != types.StatusOK {
+			t.Errorf("Expected %s to be ok, got %s (%s)", link.URL, link.Status, link.Error)
+		}
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(1500 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := newTestConfig()
+	config.Timeout = 1
+	config.Retry.MaxAttempts = 1
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{server.URL})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+	if link := result.Links[0]; link.Status != types.StatusTimeout {
+		t.Errorf("Expected a slow server to time out, got %s (%s)", link.Status, link.Error)
+	}
+}
+
+func TestMaxInFlightCoversDownloads(t *testing.T) {
+	var inFlight, maxSeen atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxSeen.Load()
+			if n <= seen || maxSeen.CompareAndSwap(seen, n) {
+				break
+			}
+		}
+		// Headers go out at once; the body trickles in
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, "done")
+	}))
+	defer server.Close()
+
+	s := NewScheduler(types.RateLimitConfig{MaxInFlight: 1}, 0)
+	client := &http.Client{Transport: s.Transport(http.DefaultTransport)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Errorf("Get() error: %v", err)
+				return
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if got := maxSeen.Load(); got != 1 {
+		t.Errorf("Expected one download at a time, saw %d", got)
+	}
+}
+
+func TestRobotsTxt(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		requested []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\nCrawl-delay: 2\n")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := newTestConfig()
+	config.RespectRobotsTxt = true
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	if result := c.checkSingleURL(context.Background(), server.URL+"/private/page", linkSource{}); result.Status != types.StatusBlockedByRobots {
+		t.Errorf("Expected status %s, got %s", types.StatusBlockedByRobots, result.Status)
+	}
+
+	mu.Lock()
+	for _, path := range requested {
+		if path == "/private/page" {
+			t.Error("Expected disallowed URL not to be requested")
+		}
+	}
+	mu.Unlock()
+
+	u, _ := url.Parse(server.URL)
+	if interval := c.scheduler.state(u.Hostname()).minInterval; interval != 2*time.Second {
+		t.Errorf("Expected crawl-delay of 2s to be applied, got %s", interval)
+	}
+}
+
+func TestCheckScope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := newTestConfig()
+	config.CheckScope = types.CheckScopeExternal
+	config.InternalDomains = []string{"*.internal.test"}
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{"http://docs.internal.test/page", server.URL})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	for _, link := range result.Links {
+		switch link.URL {
+		case "http://docs.internal.test/page":
+			if link.Scope != types.ScopeInternal || link.Status != types.StatusSkipped {
+				t.Errorf("Expected internal link to be skipped, got %s/%s", link.Scope, link.Status)
+			}
+		case server.URL:
+			if link.Scope != types.ScopeExternal || link.Status != types.StatusOK {
+				t.Errorf("Expected external link to be checked, got %s/%s", link.Scope, link.Status)
+			}
+		}
+	}
+
+	// Skipped links are counted apart from the links checked in their scope
+	if result.Internal.Checked != 0 || result.Internal.Skipped != 1 || result.External.Checked != 1 || result.External.OK != 1 {
+		t.Errorf("Unexpected scope totals: internal %+v, external %+v", result.Internal, result.External)
+	}
+	if result.TotalChecked != 2 || result.TotalSkipped != 1 {
+		t.Errorf("Expected 2 links with 1 skipped, got %d with %d skipped", result.TotalChecked, result.TotalSkipped)
+	}
+
+	// Without internal_domains, URLs checked on their own are all external
+	config.InternalDomains = nil
+	c, err = New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	result, err = c.CheckURLs(context.Background(), []string{server.URL})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+	if len(result.Links) != 1 || result.Links[0].Status != types.StatusOK {
+		t.Errorf("Expected the URL to be checked as external, got %+v", result.Links)
+	}
+}
+
+func TestCrawlExtractsResourceLinks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head>
+<link rel="stylesheet" href="/style.css">
+<meta http-equiv="refresh" content="30; url=/next">
+</head><body>
+<img src="/missing.png" srcset="/small.png 1x, /large.png 2x">
+<img src="data:image/png;base64,iVBORw0KGgo=" alt="inline">
+<a href="mailto:team@example.com">Mail</a> <a href="tel:+15550100">Call</a>
+<script src="/app.js"></script>
+</body></html>`)
+	})
+	for _, path := range []string{"/style.css", "/next", "/small.png", "/app.js"} {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := newTestConfig()
+	config.Mode = types.ModeCrawler
+	config.MaxDepth = 1
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{server.URL + "/"})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	expected := map[string]struct {
+		element   string
+		attribute string
+		status    types.LinkStatus
+	}{
+		server.URL + "/style.css":   {"link", "href", types.StatusOK},
+		server.URL + "/next":        {"meta", "content", types.StatusOK},
+		server.URL + "/missing.png": {"img", "src", types.StatusDead},
+		server.URL + "/large.png":   {"img", "srcset", types.StatusDead},
+		server.URL + "/app.js":      {"script", "src", types.StatusOK},
+	}
+
+	found := make(map[string]types.LinkResult)
+	for _, link := range result.Links {
+		found[link.URL] = link
+	}
+
+	for u, want := range expected {
+		link, ok := found[u]
+		if !ok {
+			t.Errorf("Expected %s to be checked", u)
+			continue
+		}
+		if link.Element != want.element || link.Attribute != want.attribute || link.Status != want.status {
+			t.Errorf("%s: expected %s[%s] %s, got %s[%s] %s", u,
+				want.element, want.attribute, want.status, link.Element, link.Attribute, link.Status)
+		}
+	}
+
+	// Links that are not http(s) have nothing to request
+	for u, link := range found {
+		if !strings.HasPrefix(u, "http") {
+			t.Errorf("Expected %s not to be checked, got %s", u, link.Status)
+		}
+	}
+}
+
+func TestReferrers(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/about">About</a> <a href="/gone">Old page</a>`)
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/gone"><img src="/logo.png" alt="Logo"></a> <a href="/gone">again</a>`)
+	})
+	mux.HandleFunc("/logo.png", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := newTestConfig()
+	config.Mode = types.ModeCrawler
+	config.MaxDepth = 2
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{server.URL + "/"})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	var gone *types.LinkResult
+	for i, link := range result.Links {
+		if link.URL == server.URL+"/gone" {
+			gone = &result.Links[i]
+		}
+	}
+	if gone == nil {
+		t.Fatal("Expected /gone to be checked")
+	}
+	if gone.Status != types.StatusDead {
+		t.Errorf("Expected /gone to be dead, got %s", gone.Status)
+	}
+
+	got := make(map[string]int)
+	for _, ref := range gone.Referrers {
+		if ref.Element != "a" || ref.Attribute != "href" {
+			t.Errorf("Unexpected referrer element %s[%s]", ref.Element, ref.Attribute)
+		}
+		got[ref.Page+" "+ref.Text]++
+	}
+	expected := map[string]int{
+		server.URL + "/ Old page":   1,
+		server.URL + "/about Logo":  1,
+		server.URL + "/about again": 1,
+	}
+	if len(got) != len(expected) {
+		t.Errorf("Expected referrers %v, got %v", expected, got)
+	}
+	for k, n := range expected {
+		if got[k] != n {
+			t.Errorf("Expected referrer %q %d times, got %d", k, n, got[k])
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	defaults := types.DefaultConfig().Normalize
+
+	tests := []struct {
+		name     string
+		modify   func(*types.NormalizeConfig)
+		input    string
+		expected string
+	}{
+		{"host case", nil, "https://Example.COM/a", "https://example.com/a"},
+		{"default port", nil, "https://example.com:443/a", "https://example.com/a"},
+		{"other port", nil, "http://example.com:8080/a", "http://example.com:8080/a"},
+		{"empty path", nil, "https://example.com", "https://example.com/"},
+		{"dot segments", nil, "https://example.com/a/./b/../c", "https://example.com/a/c"},
+		{"dot segment at end", nil, "https://example.com/a/b/..", "https://example.com/a/"},
+		{"tracking params", nil, "https://example.com/a?utm_source=x&id=1&fbclid=y", "https://example.com/a?id=1"},
+		{"only tracking params", nil, "https://example.com/a?utm_source=x", "https://example.com/a"},
+		{"params kept in order", nil, "https://example.com/a?b=1&a=2", "https://example.com/a?b=1&a=2"},
+		{"sorted params", func(n *types.NormalizeConfig) { n.SortParams = true }, "https://example.com/a?b=1&a=2&b=0", "https://example.com/a?a=2&b=1&b=0"},
+		{"add slash", func(n *types.NormalizeConfig) { n.TrailingSlash = types.TrailingSlashAdd }, "https://example.com/a", "https://example.com/a/"},
+		{"add slash skips files", func(n *types.NormalizeConfig) { n.TrailingSlash = types.TrailingSlashAdd }, "https://example.com/a.css", "https://example.com/a.css"},
+		{"strip slash", func(n *types.NormalizeConfig) { n.TrailingSlash = types.TrailingSlashStrip }, "https://example.com/a/", "https://example.com/a"},
+		{"strip slash keeps root", func(n *types.NormalizeConfig) { n.TrailingSlash = types.TrailingSlashStrip }, "https://example.com/", "https://example.com/"},
+		{"fragment kept", nil, "https://example.com/a#top", "https://example.com/a#top"},
+		{"strip fragment", func(n *types.NormalizeConfig) { n.StripFragment = true }, "https://example.com/a#top", "https://example.com/a"},
+		{"escapes kept", nil, "https://example.com/a%2Fb", "https://example.com/a%2Fb"},
+		{"not http", nil, "mailto:Someone@Example.com", "mailto:Someone@Example.com"},
+		{"file path", nil, "docs/../README.md", "docs/../README.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaults
+			if tt.modify != nil {
+				tt.modify(&cfg)
+			}
+			n, err := newNormalizer(cfg)
+			if err != nil {
+				t.Fatalf("newNormalizer() error: %v", err)
+			}
+			if got := n.normalize(tt.input); got != tt.expected {
+				t.Errorf("normalize(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := newNormalizer(types.NormalizeConfig{TrailingSlash: "sometimes"}); err == nil {
+		t.Error("Expected an error for an invalid trailing_slash")
+	}
+	if _, err := newNormalizer(types.NormalizeConfig{StripParams: []string{"["}}); err == nil {
+		t.Error("Expected an error for an invalid strip_params pattern")
+	}
+}
+
+func TestCrawlDeduplicatesNormalizedURLs(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/a?utm_source=news">a</a> <a href="/x/../a">b</a> <a href="/a">c</a> <a href="/?utm_source=news">home</a>`)
+	})
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.RequestURI())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/">home</a>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := newTestConfig()
+	config.Mode = types.ModeCrawler
+	config.MaxDepth = 2
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{server.URL + "/"})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	var checked int
+	for _, link := range result.Links {
+		if strings.Contains(link.URL, "/a") {
+			checked++
+			// The link is reported as first written, not in its normalized form
+			if link.URL != server.URL+"/a?utm_source=news" {
+				t.Errorf("Expected the URL as found on the page, got %s", link.URL)
+			}
+			if len(link.Referrers) != 3 {
+				t.Errorf("Expected 3 referrers, got %d", len(link.Referrers))
+			}
+		}
+	}
+	if checked != 1 {
+		t.Errorf("Expected /a to be reported once, got %d", checked)
+	}
+	// One check and one crawl, both of the URL as found
+	if !slices.Equal(requests, []string{"/a?utm_source=news", "/a?utm_source=news"}) {
+		t.Errorf("Expected 2 requests to /a?utm_source=news, got %v", requests)
+	}
+}
+
+func TestScopeRules(t *testing.T) {
+	scope := newURLScope(types.ScopeRules{
+		Include:          []string{"/docs/", "https://example.com/*.pdf"},
+		Exclude:          []string{"/docs/archive/", "/docs/*/draft-*"},
+		MaxQueryVariants: 2,
+	})
+
+	tests := []struct {
+		url      string
+		expected bool
+	}{
+		{"https://example.com/docs/", true},
+		{"https://example.com/docs/guide#install", true},
+		{"https://example.com/blog/post", false},
+		{"https://example.com/files/report.pdf", true},
+		{"https://example.com/docs/archive/old", false},
+		{"https://example.com/docs/v2/draft-api", false},
+		{"https://example.com/docs/search?q=1", true},
+		{"https://example.com/docs/search?q=2", true},
+		{"https://example.com/docs/search?q=1", true},
+		{"https://example.com/docs/search?q=3", false},
+	}
+
+	// In order: query variants are counted as URLs are seen
+	for _, tt := range tests {
+		if got := scope.allows(tt.url); got != tt.expected {
+			t.Errorf("allows(%q) = %v, expected %v", tt.url, got, tt.expected)
+		}
+	}
+}
+
+func TestFollowScope(t *testing.T) {
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/external-only">never crawled</a>`)
+	}))
+	defer external.Close()
+	externalURL := strings.Replace(external.URL, "127.0.0.1", "localhost", 1)
+
+	var mu sync.Mutex
+	crawled := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			crawled[r.URL.RequestURI()] = true
+			mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/" {
+			fmt.Fprintf(w, `<a href="/docs/a">a</a> <a href="/blog/b">b</a> <a href="/docs/archive/old">old</a>
+<a href="/docs/search?q=1">1</a> <a href="/docs/search?q=2">2</a> <a href="%s/page">ext</a>`, externalURL)
+		}
+	}))
+	defer server.Close()
+
+	config := newTestConfig()
+	config.Mode = types.ModeCrawler
+	config.MaxDepth = 3
+	config.UseSitemaps = false
+	config.Follow.Include = []string{"/docs/"}
+	config.Follow.Exclude = []string{"/docs/archive/"}
+	config.Follow.MaxQueryVariants = 1
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{server.URL + "/"})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	// Every link is checked, whether or not its page is followed
+	checked := make(map[string]bool)
+	for _, link := range result.Links {
+		checked[link.URL] = link.Status == types.StatusOK
+	}
+	for _, u := range []string{"/docs/a", "/blog/b", "/docs/archive/old", "/docs/search?q=1", "/docs/search?q=2"} {
+		if !checked[server.URL+u] {
+			t.Errorf("Expected %s to be checked ok", u)
+		}
+	}
+	if !checked[externalURL+"/page"] {
+		t.Errorf("Expected the external link to be checked ok")
+	}
+	if _, ok := checked[externalURL+"/external-only"]; ok {
+		t.Errorf("Expected the external page not to be crawled")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for page, want := range map[string]bool{
+		"/":                 true,
+		"/docs/a":           true,
+		"/docs/search?q=1":  true,
+		"/blog/b":           false,
+		"/docs/archive/old": false,
+		"/docs/search?q=2":  false,
+	} {
+		if crawled[page] != want {
+			t.Errorf("%s: expected crawled = %v, got %v", page, want, crawled[page])
+		}
+	}
+}
+
+func TestBudgets(t *testing.T) {
+	var mu sync.Mutex
+	crawled := make(map[string]bool)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			crawled[r.URL.Path] = true
+			mu.Unlock()
+		}
+		// An endless chain of pages, each with a few links
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/page/"))
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<a href="/page/%d">next</a> <a href="/file/%d-a">a</a> <a href="/file/%d-b">b</a>`, n+1, n, n)
+	})
+	mux.HandleFunc("/file/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			crawled[r.URL.Path] = true
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 64*1024))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	run := func(t *testing.T, mode types.CheckMode, budget types.BudgetConfig, urls ...string) *types.CheckResult {
+		t.Helper()
+		config := newTestConfig()
+		config.Mode = mode
+		config.MaxDepth = 0
+		config.Concurrency = 1
+		config.UseSitemaps = false
+		config.Budget = budget
+
+		c, err := New(config)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		result, err := c.CheckURLs(context.Background(), urls)
+		if err != nil {
+			t.Fatalf("CheckURLs() error: %v", err)
+		}
+		return result
+	}
+	expectStop := func(t *testing.T, result *types.CheckResult, reason types.StopReason) {
+		t.Helper()
+		if !result.Incomplete || result.StopReason != reason {
+			t.Errorf("Expected an incomplete result stopped by %s, got incomplete = %v, reason %q",
+				reason, result.Incomplete, result.StopReason)
+		}
+	}
+
+	t.Run("max pages", func(t *testing.T) {
+		mu.Lock()
+		clear(crawled)
+		mu.Unlock()
+
+		result := run(t, types.ModeCrawler, types.BudgetConfig{MaxPages: 3}, server.URL+"/page/0")
+		expectStop(t, result, types.StopMaxPages)
+
+		mu.Lock()
+		defer mu.Unlock()
+		// Every followed link counts as a page, files included
+		if len(crawled) != 3 {
+			t.Errorf("Expected 3 pages crawled, got %v", crawled)
+		}
+		// Links on crawled pages are all checked
+		for _, link := range result.Links {
+			if link.Status != types.StatusOK {
+				t.Errorf("%s: expected ok, got %s", link.URL, link.Status)
+			}
+		}
+	})
+
+	t.Run("max links", func(t *testing.T) {
+		result := run(t, types.ModeCrawler, types.BudgetConfig{MaxLinks: 4}, server.URL+"/page/0")
+		expectStop(t, result, types.StopMaxLinks)
+		if result.TotalChecked > 4 {
+			t.Errorf("Expected at most 4 links checked, got %d", result.TotalChecked)
+		}
+	})
+
+	t.Run("max duration", func(t *testing.T) {
+		start := time.Now()
+		result := run(t, types.ModeSingle, types.BudgetConfig{MaxDuration: 1}, server.URL+"/slow")
+		expectStop(t, result, types.StopMaxDuration)
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("Expected the check to stop after about 1s, took %v", elapsed)
+		}
+	})
+
+	t.Run("max host requests", func(t *testing.T) {
+		result := run(t, types.ModeSingle, types.BudgetConfig{MaxHostRequests: 2},
+			server.URL+"/file/1", server.URL+"/file/2", server.URL+"/file/3")
+		expectStop(t, result, types.StopMaxHostRequests)
+
+		var ok, skipped int
+		for _, link := range result.Links {
+			switch link.Status {
+			case types.StatusOK:
+				ok++
+			case types.StatusSkipped:
+				skipped++
+				if !strings.Contains(link.Error, "budget.max_host_requests (2)") {
+					t.Errorf("Expected the skip explained by the host budget, got %q", link.Error)
+				}
+			}
+		}
+		if ok != 2 || skipped != 1 {
+			t.Errorf("Expected 2 ok and 1 skipped, got %d ok and %d skipped", ok, skipped)
+		}
+		// The skipped link is reported but was never requested
+		if result.TotalChecked != 3 || result.TotalSkipped != 1 {
+			t.Errorf("Expected 3 links with 1 skipped, got %d with %d skipped", result.TotalChecked, result.TotalSkipped)
+		}
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		result := run(t, types.ModeCrawler, types.BudgetConfig{MaxBytes: 1024}, server.URL+"/large")
+		expectStop(t, result, types.StopMaxBytes)
+	})
+
+	t.Run("within budget", func(t *testing.T) {
+		result := run(t, types.ModeSingle, types.BudgetConfig{MaxLinks: 5, MaxPages: 5}, server.URL+"/file/1")
+		if result.Incomplete {
+			t.Errorf("Expected a complete result, got stop reason %q", result.StopReason)
+		}
+	})
+}
+
+func TestCrawlPolicies(t *testing.T) {
+	pages := map[string]string{
+		"/": `<a href="/nf" rel="external nofollow">nf</a> <a href="/both" rel="nofollow">both</a>
+<a href="/dup?sort=1">dup</a> <a href="/meta">meta</a> <a href="/header">header</a> <a href="/scoped">scoped</a>`,
+		"/dup":    `<link rel="canonical" href="/canon"><a href="/dup-only">x</a>`,
+		"/canon":  `<link rel="canonical" href="/canon"><a href="/both">both</a>`,
+		"/meta":   `<meta name="ROBOTS" content="noindex, nofollow"><a href="/meta-only">x</a>`,
+		"/header": `<a href="/header-only">x</a>`,
+		"/scoped": `<a href="/scoped-child">x</a>`,
+	}
+
+	run := func(t *testing.T, follow types.FollowConfig) (map[string]types.LinkResult, map[string]bool) {
+		t.Helper()
+		var mu sync.Mutex
+		crawled := make(map[string]bool)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				mu.Lock()
+				crawled[r.URL.Path] = true
+				mu.Unlock()
+			}
+			switch r.URL.Path {
+			case "/header":
+				w.Header().Set("X-Robots-Tag", "nofollow")
+			case "/scoped":
+				w.Header().Set("X-Robots-Tag", "otherbot: nofollow")
+			}
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, pages[r.URL.Path])
+		}))
+		defer server.Close()
+
+		config := newTestConfig()
+		config.Mode = types.ModeCrawler
+		// / -> /dup -> /canon -> /both
+		config.MaxDepth = 4
+		config.UseSitemaps = false
+		config.Follow = follow
+
+		c, err := New(config)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		result, err := c.CheckURLs(context.Background(), []string{server.URL + "/"})
+		if err != nil {
+			t.Fatalf("CheckURLs() error: %v", err)
+		}
+
+		links := make(map[string]types.LinkResult)
+		for _, link := range result.Links {
+			u, _ := url.Parse(link.URL)
+			links[u.RequestURI()] = link
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return links, crawled
+	}
+
+	t.Run("respected", func(t *testing.T) {
+		links, crawled := run(t, types.DefaultConfig().Follow)
+
+		expected := map[string]struct {
+			reason  types.NoFollowReason
+			crawled bool
+		}{
+			"/nf":           {types.NoFollowRel, false},
+			"/both":         {"", true},
+			"/canon":        {"", true},
+			"/dup-only":     {types.NoFollowCanonical, false},
+			"/meta-only":    {types.NoFollowRobots, false},
+			"/header-only":  {types.NoFollowRobots, false},
+			"/scoped-child": {"", true},
+		}
+		for path, want := range expected {
+			link, ok := links[path]
+			if !ok {
+				t.Errorf("Expected %s to be checked", path)
+				continue
+			}
+			if link.Status != types.StatusOK {
+				t.Errorf("%s: expected ok, got %s", path, link.Status)
+			}
+			if link.NotFollowed != want.reason {
+				t.Errorf("%s: expected not followed %q, got %q", path, want.reason, link.NotFollowed)
+			}
+			if crawled[path] != want.crawled {
+				t.Errorf("%s: expected crawled = %v, got %v", path, want.crawled, crawled[path])
+			}
+		}
+	})
+
+	t.Run("ignored", func(t *testing.T) {
+		links, crawled := run(t, types.FollowConfig{})
+		for _, path := range []string{"/nf", "/dup-only", "/meta-only", "/header-only"} {
+			if !crawled[path] {
+				t.Errorf("Expected %s to be crawled", path)
+			}
+			if reason := links[path].NotFollowed; reason != "" {
+				t.Errorf("%s: expected no not-followed reason, got %q", path, reason)
+			}
+		}
+	})
+}
+
+func TestRobotsNofollow(t *testing.T) {
+	tests := []struct {
+		values   []string
+		expected bool
+	}{
+		{[]string{"noindex, nofollow"}, true},
+		{[]string{"NONE"}, true},
+		{[]string{"noindex"}, false},
+		{[]string{"googlebot: nofollow"}, false},
+		{[]string{"unavailable_after: 2030-01-01, nofollow"}, true},
+		{[]string{"index", "nofollow"}, true},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := robotsNofollow(tt.values); got != tt.expected {
+			t.Errorf("robotsNofollow(%q) = %v, expected %v", tt.values, got, tt.expected)
+		}
+	}
+}
+
+func TestFragments(t *testing.T) {
+	var docsFetches atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body>
+<h1 id="intro">Intro</h1>
+<a href="#intro">here</a>
+<a href="#gone">gone</a>
+<a href="/docs#setup">setup</a>
+<a href="/docs#nope">nope</a>
+</body></html>`)
+	})
+	mux.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			docsFetches.Add(1)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<html><body><a name="setup"></a></body></html>`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := newTestConfig()
+	config.Mode = types.ModeCrawler
+	config.MaxDepth = 1
+	config.CheckFragments = true
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{server.URL + "/"})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	expected := map[string]types.LinkStatus{
+		server.URL + "/#intro":     types.StatusOK,
+		server.URL + "/#gone":      types.StatusMissingFragment,
+		server.URL + "/docs#setup": types.StatusOK,
+		server.URL + "/docs#nope":  types.StatusMissingFragment,
+	}
+
+	found := make(map[string]types.LinkStatus)
+	for _, link := range result.Links {
+		found[link.URL] = link.Status
+	}
+	for u, want := range expected {
+		if found[u] != want {
+			t.Errorf("%s: expected %s, got %q", u, want, found[u])
+		}
+	}
+
+	if result.TotalDead != 2 {
+		t.Errorf("Expected missing fragments to count as dead, got %d dead", result.TotalDead)
+	}
+	if n := docsFetches.Load(); n != 1 {
+		t.Errorf("Expected /docs to be fetched once for its anchors, got %d", n)
+	}
+}
+
+func TestCrawlFollowsFragmentLinksOnce(t *testing.T) {
+	var docsFetches atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<a href="/docs/#install">install</a> <a href="/docs/#nope">nope</a> <a href="/docs/">docs</a>`)
+	})
+	mux.HandleFunc("/docs/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			docsFetches.Add(1)
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<h2 id="install">Install</h2> <a href="/other">other</a>`)
+	})
+	mux.HandleFunc("/other", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := newTestConfig()
+	config.Mode = types.ModeCrawler
+	config.MaxDepth = 2
+	// Fragment checks would fetch /docs/ on their own
+	config.CheckFragments = false
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{server.URL + "/"})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	if n := docsFetches.Load(); n != 1 {
+		t.Errorf("Expected /docs/ to be crawled once, got %d fetches", n)
+	}
+	for _, link := range result.Links {
+		if link.URL != server.URL+"/other" {
+			continue
+		}
+		if len(link.Referrers) != 1 || link.Referrers[0].Page != server.URL+"/docs/" {
+			t.Errorf("Expected one referrer from /docs/, got %+v", link.Referrers)
+		}
+		return
+	}
+	t.Error("Expected /other to be checked")
+}
+
+func TestRedirectChains(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/moved", http.RedirectHandler("/temp", http.StatusMovedPermanently))
+	mux.Handle("/temp", http.RedirectHandler("/final", http.StatusFound))
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/loop-a", http.RedirectHandler("/loop-b", http.StatusTemporaryRedirect))
+	mux.Handle("/loop-b", http.RedirectHandler("/loop-a", http.StatusTemporaryRedirect))
+	mux.HandleFunc("/long/", func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		fmt.Sscanf(r.URL.Path, "/long/%d", &n)
+		http.Redirect(w, r, fmt.Sprintf("/long/%d", n+1), http.StatusPermanentRedirect)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := newTestConfig()
+	config.MaxRedirects = 3
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{
+		server.URL + "/moved",
+		server.URL + "/loop-a",
+		server.URL + "/long/0",
+	})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	found := make(map[string]types.LinkResult)
+	for _, link := range result.Links {
+		found[link.URL] = link
+	}
+
+	moved := found[server.URL+"/moved"]
+	if moved.Status != types.StatusOK || moved.RedirectType != types.RedirectPermanent {
+		t.Errorf("/moved: expected ok via a permanent redirect, got %s %q", moved.Status, moved.RedirectType)
+	}
+	wantHops := []types.RedirectHop{
+		{URL: server.URL + "/moved", StatusCode: http.StatusMovedPermanently},
+		{URL: server.URL + "/temp", StatusCode: http.StatusFound},
+	}
+	if len(moved.Redirects) != len(wantHops) {
+		t.Fatalf("/moved: expected %d hops, got %+v", len(wantHops), moved.Redirects)
+	}
+	for i, hop := range wantHops {
+		if moved.Redirects[i] != hop {
+			t.Errorf("/moved hop %d: expected %+v, got %+v", i, hop, moved.Redirects[i])
+		}
+	}
+	if moved.RedirectURL != server.URL+"/final" {
+		t.Errorf("/moved: expected to end at /final, got %s", moved.RedirectURL)
+	}
+
+	if status := found[server.URL+"/loop-a"].Status; status != types.StatusRedirectLoop {
+		t.Errorf("/loop-a: expected %s, got %s", types.StatusRedirectLoop, status)
+	}
+	long := found[server.URL+"/long/0"]
+	if long.Status != types.StatusTooManyRedirect {
+		t.Errorf("/long/0: expected %s, got %s", types.StatusTooManyRedirect, long.Status)
+	}
+	if len(long.Redirects) != config.MaxRedirects+1 {
+		t.Errorf("/long/0: expected %d hops, got %d", config.MaxRedirects+1, len(long.Redirects))
+	}
+
+	if result.TotalMoved != 1 {
+		t.Errorf("Expected 1 permanently moved link, got %d", result.TotalMoved)
+	}
+}
+
+func TestStatusRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/members":
+			w.WriteHeader(http.StatusForbidden)
+		case "/busy":
+			w.WriteHeader(999)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config := newTestConfig()
+	config.StatusRules = []types.StatusRule{
+		{Name: "login wall", Codes: []int{401, 403}, Hosts: []string{"127.0.0.1"}, Status: types.StatusOK},
+		{Ranges: []string{"900-999"}, Status: types.StatusRateLimited},
+		{Codes: []int{404}, Pattern: "/elsewhere$", Status: types.StatusSkipped},
+	}
+
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{
+		server.URL + "/members",
+		server.URL + "/busy",
+		server.URL + "/gone",
+	})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	expected := map[string]struct {
+		status types.LinkStatus
+		rule   string
+	}{
+		server.URL + "/members": {types.StatusOK, "login wall"},
+		server.URL + "/busy":    {types.StatusRateLimited, "900-999 -> rate_limited"},
+		server.URL + "/gone":    {types.StatusDead, ""},
+	}
+	for _, link := range result.Links {
+		want := expected[link.URL]
+		if link.Status != want.status || link.Rule != want.rule {
+			t.Errorf("%s: expected %s (rule %q), got %s (rule %q)", link.URL, want.status, want.rule, link.Status, link.Rule)
+		}
+	}
+
+	if result.TotalLimited != 1 {
+		t.Errorf("Expected 1 rate limited link, got %d", result.TotalLimited)
+	}
+
+	config.StatusRules = []types.StatusRule{{Codes: []int{403}, Status: "fine"}}
+	if _, err := New(config); err == nil {
+		t.Error("Expected an error for a rule with an unknown status")
+	}
+}
+
+func TestResumeCrawl(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		hits     = make(map[string]int)
+		blockB   atomic.Bool
+		bBlocked = make(chan struct{}, 1)
+	)
+	blockB.Store(true)
+
+	mux := http.NewServeMux()
+	page := func(path, body string) {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits[path]++
+			mu.Unlock()
+			if path == "/b" && blockB.Load() {
+				bBlocked <- struct{}{}
+				<-r.Context().Done()
+				return
+			}
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, body)
+		})
+	}
+	page("/", `<a href="/a">a</a> <a href="/b">b</a>`)
+	page("/a", `<a href="/c">c</a>`)
+	page("/b", `<a href="/d">d</a>`)
+	page("/c", `done`)
+	page("/d", `done`)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	state := t.TempDir() + "/crawl.state"
+	config := newTestConfig()
+	config.Mode = types.ModeCrawler
+	config.Concurrency = 1
+
+	// First run: interrupted while /b hangs, after /a and /c are done
+	first, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := first.StartJournal(state, []string{server.URL + "/"}); err != nil {
+		t.Fatalf("StartJournal() error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-bBlocked
+		time.Sleep(200 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := first.CheckURLs(ctx, []string{server.URL + "/"}); err != context.Canceled {
+		t.Fatalf("Expected the first run to be cancelled, got %v", err)
+	}
+	first.Close()
+
+	// Second run: resumes without rechecking /a or /c
+	blockB.Store(false)
+	mu.Lock()
+	hitsBefore := map[string]int{"/a": hits["/a"], "/c": hits["/c"]}
+	mu.Unlock()
+
+	second, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	seeds, err := second.ResumeJournal(state)
+	if err != nil {
+		t.Fatalf("ResumeJournal() error: %v", err)
+	}
+	defer second.Close()
+
+	result, err := second.CheckURLs(context.Background(), seeds)
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	mu.Lock()
+	for path, before := range hitsBefore {
+		if hits[path] != before {
+			t.Errorf("Expected %s not to be requested again, got %d new requests", path, hits[path]-before)
+		}
+	}
+	mu.Unlock()
+
+	found := make(map[string]types.LinkStatus)
+	for _, link := range result.Links {
+		if _, dup := found[link.URL]; dup {
+			t.Errorf("%s reported twice", link.URL)
+		}
+		found[link.URL] = link.Status
+		// The root page was cut off mid-scrape and scraped again
+		if len(link.Referrers) != 1 {
+			t.Errorf("Expected %s to have 1 referrer, got %+v", link.URL, link.Referrers)
+		}
+	}
+	for _, path := range []string{"/a", "/b", "/c", "/d"} {
+		if found[server.URL+path] != types.StatusOK {
+			t.Errorf("Expected %s to be ok, got %q", path, found[server.URL+path])
+		}
+	}
+}
+
+func TestJournalWriteError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c, err := New(newTestConfig())
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if err := c.StartJournal(filepath.Join(t.TempDir(), "check.state"), []string{server.URL}); err != nil {
+		t.Fatalf("StartJournal() error: %v", err)
+	}
+	// Every later write fails
+	c.journal.f.Close()
+
+	if _, err := c.CheckURLs(context.Background(), []string{server.URL}); err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+	if err := c.Close(); err == nil || !strings.Contains(err.Error(), "failed to write state file") {
+		t.Errorf("Expected Close to report the failed write, got %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Expected closing again to do nothing, got %v", err)
+	}
+}
+
+func TestSitemaps(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	html := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, body)
+		}
+	}
+	mux.HandleFunc("/{$}", html(`<a href="/linked">linked</a> <a href="/extra">extra</a>`))
+	mux.HandleFunc("/linked", html(`linked`))
+	mux.HandleFunc("/extra", html(`extra`))
+	mux.HandleFunc("/orphan", html(`<a href="/deep">deep</a>`))
+	mux.HandleFunc("/deep", html(`deep`))
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "User-agent: *\nDisallow:\nSitemap: %s/sitemap_index.xml.gz\n", server.URL)
+	})
+
+	// The index is gzipped without a Content-Encoding, as static hosts serve .gz files
+	var index bytes.Buffer
+	gz := gzip.NewWriter(&index)
+	fmt.Fprintf(gz, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%s/pages.xml</loc></sitemap>
+</sitemapindex>`, server.URL)
+	gz.Close()
+	mux.HandleFunc("/sitemap_index.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Write(index.Bytes())
+	})
+	mux.HandleFunc("/pages.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%[1]s/</loc></url>
+  <url><loc>%[1]s/linked</loc></url>
+  <url><loc>%[1]s/orphan</loc></url>
+</urlset>`, server.URL)
+	})
+
+	config := newTestConfig()
+	config.Mode = types.ModeCrawler
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{server.URL + "/"})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	report := result.Sitemap
+	if report == nil {
+		t.Fatal("Expected a sitemap report")
+	}
+	if len(report.Sitemaps) != 2 || report.Pages != 3 {
+		t.Errorf("Expected 3 pages in 2 sitemaps, got %d in %v", report.Pages, report.Sitemaps)
+	}
+	if want := []string{server.URL + "/orphan"}; fmt.Sprint(report.Orphans) != fmt.Sprint(want) {
+		t.Errorf("Expected orphans %v, got %v", want, report.Orphans)
+	}
+	if want := []string{server.URL + "/deep", server.URL + "/extra"}; fmt.Sprint(report.Unlisted) != fmt.Sprint(want) {
+		t.Errorf("Expected unlisted pages %v, got %v", want, report.Unlisted)
+	}
+
+	// The orphan is crawled from the sitemap, so its own links are checked
+	found := false
+	for _, link := range result.Links {
+		if link.URL == server.URL+"/deep" {
+			found = link.FoundOn == server.URL+"/orphan"
+		}
+	}
+	if !found {
+		t.Error("Expected /deep to be checked from the orphan page")
+	}
+}
+
+func TestDirectoryMode(t *testing.T) {
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer external.Close()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"index.html":      `<a href="/about">about</a> <a href="docs/">docs</a> <a href="/missing">missing</a> <a href="` + external.URL + `/ok">ext</a>`,
+		"about.html":      `<a href="/docs/intro#setup">setup</a> <a href="/index.html">home</a> <a href="/docs/index.html">docs</a>`,
+		"docs/index.html": `<a href="intro">intro</a> <img src="../nope.png">`,
+		"docs/intro.html": `<h2 id="setup">Setup</h2>`,
+		"orphan.html":     `<a href="/gone/">gone</a>`,
+	}
+	for name, body := range files {
+		name = root + "/" + name
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := newTestConfig()
+	config.Mode = types.ModeDirectory
+	config.Directory.Root = root
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{config.Directory.BaseURL})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	found := make(map[string]types.LinkResult)
+	for _, link := range result.Links {
+		found[link.URL] = link
+	}
+
+	tests := []struct {
+		url    string
+		status types.LinkStatus
+		error  string
+	}{
+		{"http://localhost/about", types.StatusOK, ""},
+		{"http://localhost/docs/", types.StatusOK, ""},
+		{"http://localhost/docs/intro", types.StatusOK, ""},
+		{"http://localhost/docs/intro#setup", types.StatusOK, ""},
+		{"http://localhost/missing", types.StatusDead, "missing file missing.html"},
+		{"http://localhost/nope.png", types.StatusDead, "missing file nope.png"},
+		{"http://localhost/gone/", types.StatusDead, "missing file gone/index.html"},
+		{external.URL + "/ok", types.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		link, ok := found[tt.url]
+		if !ok {
+			t.Errorf("Expected %s to be checked", tt.url)
+			continue
+		}
+		if link.Status != tt.status || link.Error != tt.error {
+			t.Errorf("%s: expected %q (%q), got %q (%q)", tt.url, tt.status, tt.error, link.Status, link.Error)
+		}
+	}
+
+	// Index files are the same pages as their directories, crawled once
+	for link, page := range map[string]string{
+		"http://localhost/about":      "http://localhost/",
+		"http://localhost/docs/intro": "http://localhost/docs/",
+	} {
+		refs := found[link].Referrers
+		if len(refs) != 1 || refs[0].Page != page {
+			t.Errorf("Expected %s to have one referrer from %s, got %+v", link, page, refs)
+		}
+	}
+
+	// A directory without its trailing slash redirects like a file server would
+	resp, err := c.client.Get("http://localhost/docs")
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.Request.URL.Path != "/docs/" {
+		t.Errorf("Expected /docs to redirect to /docs/, got %s", resp.Request.URL.Path)
+	}
+}
+
+func TestParseMarkdown(t *testing.T) {
+	content := "# Title\n" +
+		"See [the guide](guide.md#install) and ![logo](img/logo.png \"Logo\").\n" +
+		"[![badge](https://img.example/b.svg)](https://ci.example/)\n" +
+		"A <https://auto.example/x> autolink, a bare https://bare.example/page. and `[code](nope.md)`.\n" +
+		"```\n" +
+		"[fenced](nope.md)\n" +
+		"```\n" +
+		"[ref]: <docs/ref page.md>\n" +
+		"<a href=\"../up.md\">up</a> ünï [x](mailto:a@b.example)\n"
+
+	want := []string{
+		"2:17 guide.md#install",
+		"2:47 img/logo.png",
+		"3:11 https://img.example/b.svg",
+		"3:39 https://ci.example/",
+		"4:4 https://auto.example/x",
+		"4:45 https://bare.example/page",
+		"8:9 docs/ref page.md",
+		"9:10 ../up.md",
+		"9:35 mailto:a@b.example",
+	}
+
+	var got []string
+	for _, l := range parseMarkdown(content) {
+		got = append(got, fmt.Sprintf("%d:%d %s", l.line, l.column, l.target))
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("parseMarkdown():\n got  %q\n want %q", got, want)
+	}
+}
+
+func TestParseRST(t *testing.T) {
+	content := "Title\n=====\n\n" +
+		"Read `the docs <https://docs.example/>`_ or `intro <intro.rst>`__.\n" +
+		".. _python: https://python.example/\n" +
+		".. _alias: python_\n" +
+		".. image:: img/diagram.png\n" +
+		"Example::\n" +
+		"\n" +
+		"    https://literal.example/\n" +
+		"\n" +
+		"See https://bare.example/.\n"
+
+	want := []string{
+		"4:17 https://docs.example/",
+		"4:53 intro.rst",
+		"5:13 https://python.example/",
+		"7:12 img/diagram.png",
+		"12:5 https://bare.example/",
+	}
+
+	var got []string
+	for _, l := range parseRST(content) {
+		got = append(got, fmt.Sprintf("%d:%d %s", l.line, l.column, l.target))
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("parseRST():\n got  %q\n want %q", got, want)
+	}
+}
+
+func TestFilesMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"README.md": "# Project\n\n" +
+			"See [setup](docs/setup.md#first-steps) and [usage](docs/setup.md#usage).\n" +
+			"[missing](docs/nope.md) [remote](" + server.URL + "/ok) [gone](" + server.URL + "/gone)\n" +
+			"[rooted](/docs/setup.md)\n",
+		"docs/setup.md":  "## First Steps\n\n[back](../README.md#project) [top](#first-steps)\n",
+		"docs/index.rst": "`Setup <setup.md>`_\n",
+		"docs/notes.txt": "[ignored](nowhere.md)\n",
+	}
+	for name, body := range files {
+		name = filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := newTestConfig()
+	config.Mode = types.ModeFiles
+	c, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	result, err := c.CheckURLs(context.Background(), []string{root})
+	if err != nil {
+		t.Fatalf("CheckURLs() error: %v", err)
+	}
+
+	found := make(map[string]types.LinkResult)
+	for _, link := range result.Links {
+		found[strings.TrimPrefix(link.URL, filepath.ToSlash(root)+"/")] = link
+	}
+
+	readme := filepath.Join(root, "README.md")
+	tests := []struct {
+		url      string
+		status   types.LinkStatus
+		location string
+	}{
+		{"docs/setup.md#first-steps", types.StatusOK, readme + ":3:13"},
+		{"docs/setup.md#usage", types.StatusMissingFragment, readme + ":3:52"},
+		{"docs/nope.md", types.StatusDead, readme + ":4:11"},
+		{server.URL + "/ok", types.StatusOK, readme + ":4:34"},
+		{server.URL + "/gone", types.StatusDead, ""},
+		{"README.md#project", types.StatusOK, ""},
+		{"docs/setup.md", types.StatusOK, filepath.Join(root, "docs/index.rst") + ":1:9"},
+		{"/docs/setup.md", types.StatusSkipped, readme + ":5:10"},
+	}
+	for _, tt := range tests {
+		link, ok := found[tt.url]
+		if !ok {
+			t.Errorf("Expected %s to be checked", tt.url)
+			continue
+		}
+		if link.Status != tt.status {
+			t.Errorf("%s: expected status %q, got %q (%s)", tt.url, tt.status, link.Status, link.Error)
+		}
+		if tt.location != "" && (link.Location == nil || link.Location.String() != tt.location) {
+			t.Errorf("%s: expected location %s, got %v", tt.url, tt.location, link.Location)
+		}
+	}
+	if link := found["/docs/setup.md"]; !strings.Contains(link.Error, "root-relative") {
+		t.Errorf("Expected the root-relative link skipped with a reason, got %q", link.Error)
+	}
+	if _, ok := found["docs/nowhere.md"]; ok {
+		t.Error("Expected .txt files not to be read")
+	}
+}
+
+func TestParseSrcset(t *testing.T) {
+	tests := map[string][]string{
+		"a.png 1x, b.png 2x": {"a.png", "b.png"},
+		"a.png":              {"a.png"},
+		"a.png 1x,b.png 2x":  {"a.png", "b.png"},
+		"a.png,b.png 2x":     {"a.png,b.png"},
+		"https://cdn.test/img.jpg?w=100,h=200 1x":    {"https://cdn.test/img.jpg?w=100,h=200"},
+		"/a.jpg?s=1,2 480w, /b.jpg?s=3,4 800w":       {"/a.jpg?s=1,2", "/b.jpg?s=3,4"},
+		"data:image/png;base64,AAAA 1x, real.png 2x": {"real.png"},
+		"a.png (future, descriptor) 1x, b.png":       {"a.png", "b.png"},
+		" , ":                                        nil,
+	}
+
+	for value, expected := range tests {
+		if got := parseSrcset(value); !slices.Equal(got, expected) {
+			t.Errorf("parseSrcset(%q) = %q, expected %q", value, got, expected)
+		}
+	}
+}
+
+func TestParseRefresh(t *testing.T) {
+	tests := map[string]string{
+		"5; url=/next":       "/next",
+		"0;URL='/quoted'":    "/quoted",
+		"3, https://a.test/": "https://a.test/",
+		"10":                 "",
+	}
+
+	for value, expected := range tests {
+		if got := parseRefresh(value); got != expected {
+			t.Errorf("parseRefresh(%q) = %q, expected %q", value, got, expected)
+		}
+	}
+}
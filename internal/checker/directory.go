@@ -0,0 +1,280 @@
+package checker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// missingFileHeader is set on the 404 responses of a site served from disk,
+// naming the file the link was expected to resolve to
+const missingFileHeader = "X-Unlinked-Missing-File"
+
+// siteTransport serves requests under a site's base URL from its build
+// directory on disk and passes every other request on to next
+type siteTransport struct {
+	root       string // absolute path of the build directory
+	base       *url.URL
+	indexFiles []string
+	cleanURLs  bool
+	next       http.RoundTripper
+}
+
+func newSiteTransport(dir types.DirectoryConfig, next http.RoundTripper) (*siteTransport, error) {
+	root, err := filepath.Abs(dir.Root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory %q: %w", dir.Root, err)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory %q: %w", dir.Root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("invalid directory %q: not a directory", dir.Root)
+	}
+
+	base, err := url.Parse(dir.BaseURL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: expected an http(s) URL", dir.BaseURL)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+
+	indexFiles := dir.IndexFiles
+	if len(indexFiles) == 0 {
+		indexFiles = []string{"index.html"}
+	}
+
+	return &siteTransport{
+		root:       root,
+		base:       base,
+		indexFiles: indexFiles,
+		cleanURLs:  dir.CleanURLs,
+		next:       next,
+	}, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *siteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rel, ok := t.relPath(req.URL)
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	file, isDir := t.resolve(rel)
+	switch {
+	case isDir:
+		// Like any static file server, send directory URLs to their slash
+		// form so relative links on the index page resolve correctly
+		loc := *req.URL
+		loc.Path += "/"
+		resp := t.response(req, http.StatusMovedPermanently, nil)
+		resp.Header.Set("Location", loc.String())
+		return resp, nil
+	case file == "":
+		resp := t.response(req, http.StatusNotFound, nil)
+		resp.Header.Set(missingFileHeader, t.expectedFile(rel))
+		return resp, nil
+	}
+
+	body, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	resp := t.response(req, http.StatusOK, body)
+	contentType := mime.TypeByExtension(filepath.Ext(file))
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	resp.Header.Set("Content-Type", contentType)
+	return resp, nil
+}
+
+// response builds a response to req; HEAD responses carry no body
+func (t *siteTransport) response(req *http.Request, code int, body []byte) *http.Response {
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          http.NoBody,
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+	if req.Method != http.MethodHead && len(body) > 0 {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return resp
+}
+
+// relPath returns the slash-separated path of u below the base URL, and
+// whether u belongs to the site at all
+func (t *siteTransport) relPath(u *url.URL) (string, bool) {
+	if u.Scheme != t.base.Scheme || !strings.EqualFold(u.Host, t.base.Host) {
+		return "", false
+	}
+
+	p := u.Path
+	if p == "" {
+		p = "/"
+	}
+	if p+"/" == t.base.Path {
+		// The site root without its trailing slash; resolves to a redirect
+		return ".", true
+	}
+	if !strings.HasPrefix(p, t.base.Path) {
+		return "", false
+	}
+	return strings.TrimPrefix(p, t.base.Path), true
+}
+
+// resolve maps a site path to the file that serves it, applying the index
+// file and clean URL rules. It reports a directory requested without its
+// trailing slash separately, and returns "" when nothing serves the path.
+func (t *siteTransport) resolve(rel string) (file string, isDir bool) {
+	// Clean against a rooted path so ".." can never leave the build directory
+	clean := path.Clean("/" + rel)
+	name := filepath.Join(t.root, filepath.FromSlash(clean))
+	dirPath := rel == "" || strings.HasSuffix(rel, "/")
+
+	info, err := os.Stat(name)
+	if err == nil && !info.IsDir() && !dirPath {
+		return name, false
+	}
+
+	// /about is served by about.html
+	if t.cleanURLs && !dirPath && clean != "/" && path.Ext(clean) == "" {
+		if isFile(name + ".html") {
+			return name + ".html", false
+		}
+	}
+
+	if err == nil && info.IsDir() {
+		for _, index := range t.indexFiles {
+			if isFile(filepath.Join(name, index)) {
+				if !dirPath {
+					return "", true
+				}
+				return filepath.Join(name, index), false
+			}
+		}
+	}
+	return "", false
+}
+
+// indexPage maps the URL of an index file, such as /docs/index.html, to the
+// directory URL served by the same file, /docs/. Other URLs are returned
+// unchanged.
+func (t *siteTransport) indexPage(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	rel, ok := t.relPath(u)
+	if !ok {
+		return rawURL
+	}
+	dir, base := path.Split(rel)
+	if !slices.Contains(t.indexFiles, base) {
+		return rawURL
+	}
+	if file, _ := t.resolve(rel); file == "" {
+		return rawURL
+	} else if dirFile, _ := t.resolve(dir); dirFile != file {
+		return rawURL
+	}
+	u.Path = strings.TrimSuffix(u.Path, base)
+	u.RawPath = ""
+	return u.String()
+}
+
+// expectedFile names the file a missing path should have resolved to,
+// relative to the build directory
+func (t *siteTransport) expectedFile(rel string) string {
+	clean := strings.TrimPrefix(path.Clean("/"+rel), "/")
+	switch {
+	case rel == "" || strings.HasSuffix(rel, "/"):
+		return path.Join(clean, t.indexFiles[0])
+	case t.cleanURLs && path.Ext(clean) == "":
+		return clean + ".html"
+	default:
+		return clean
+	}
+}
+
+// pages returns the URL of every HTML file in the build directory, in the
+// form links to it are expected to use
+func (t *siteTransport) pages() ([]string, error) {
+	var pages []string
+	err := filepath.WalkDir(t.root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(name))
+		if d.IsDir() || (ext != ".html" && ext != ".htm") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(t.root, name)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		dir, base := path.Split(rel)
+		switch {
+		case base == t.indexFiles[0]:
+			rel = dir
+		case t.cleanURLs && ext == ".html":
+			rel = strings.TrimSuffix(rel, ".html")
+		}
+
+		page := *t.base
+		page.Path += rel
+		pages = append(pages, page.String())
+		return nil
+	})
+	return pages, err
+}
+
+// seedFromDirectory queues every HTML page of the build directory as a crawl
+// root, so that pages nothing links to are checked too
+func (c *Checker) seedFromDirectory(collector *colly.Collector) error {
+	if c.site == nil {
+		return nil
+	}
+
+	pages, err := c.site.pages()
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+	for _, page := range pages {
+		if !c.followScope.allows(page) || !c.claimPage(page) {
+			continue
+		}
+		if err := collector.Visit(page); err == nil {
+			c.journal.queued(page, 1)
+		}
+	}
+	return nil
+}
+
+func isFile(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,232 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// linkSource describes where a link was found
+type linkSource struct {
+	foundOn   string                // page the link appeared on
+	element   string                // HTML element name, e.g. "img"
+	attribute string                // attribute name, e.g. "src"
+	location  *types.SourceLocation // position in a source file, in files mode
+	text      string                // anchor text, or alt text for images
+}
+
+// foundLink is a link queued for checking together with where it was found.
+// Links from source files to files next to them are checked on disk; file is
+// then the path of the target.
+type foundLink struct {
+	url      string
+	file     string
+	fragment string
+	skip     string // files mode: why the link is reported without being checked
+	src      linkSource
+}
+
+// registerExtractors wires every configured extractor into the collector
+func (c *Checker) registerExtractors(ctx context.Context, collector *colly.Collector) {
+	extractors := c.config.Extractors
+	if len(extractors) == 0 {
+		extractors = types.DefaultExtractors()
+	}
+
+	for _, ex := range extractors {
+		selector := fmt.Sprintf("%s[%s]", ex.Element, ex.Attribute)
+
+		collector.OnHTML(selector, func(e *colly.HTMLElement) {
+			src := linkSource{
+				foundOn:   e.Request.URL.String(),
+				element:   e.Name,
+				attribute: ex.Attribute,
+				text:      elementText(e),
+			}
+
+			for _, raw := range extractLinks(ex.Kind, e.Attr(ex.Attribute)) {
+				// Same-page anchors only need their fragment checked
+				samePage := strings.HasPrefix(raw, "#")
+				if samePage && (raw == "#" || !c.config.CheckFragments) {
+					continue
+				}
+
+				link := e.Request.AbsoluteURL(raw)
+				if samePage {
+					link = pageKey(e.Request.URL) + raw
+				}
+				// mailto:, tel:, javascript: and inline data: URLs have nothing to request
+				if !isHTTPLink(link) {
+					continue
+				}
+
+				// Check the link
+				c.checkSingleURL(ctx, link, src)
+
+				// Visit the link if in crawler mode (to find more links),
+				// unless the crawl has been cancelled
+				if ctx.Err() != nil {
+					return
+				}
+				if !ex.Follow || samePage || !c.crawling() {
+					continue
+				}
+				// A link to a place on another page only had its fragment
+				// checked; the page itself is what is crawled
+				page := pageKeyString(link)
+				c.sitemaps.markLinked(c.pageKey(page))
+				if !c.shouldFollow(page) {
+					continue
+				}
+				if reason := c.noFollowReason(e); reason != "" {
+					c.suppressFollow(c.pageKey(page), reason)
+					continue
+				}
+				c.allowFollow(c.pageKey(page))
+				if c.robotsAllowed(ctx, page) {
+					c.visit(e.Request, page)
+				}
+			}
+		})
+	}
+}
+
+// visit queues the page link points at for crawling from the page of r and
+// records it in the journal
+func (c *Checker) visit(r *colly.Request, link string) {
+	depth := pageDepth(r) + 1
+	if c.config.MaxDepth > 0 && depth > c.config.MaxDepth {
+		return
+	}
+	link = pageKeyString(link)
+	if !c.claimPage(link) {
+		return
+	}
+	if err := r.Visit(link); err == nil {
+		c.journal.queued(link, depth)
+	}
+}
+
+// maxLinkText caps the anchor text recorded for a referrer, in characters
+const maxLinkText = 100
+
+// elementText returns the text a reader sees for a link element: its
+// content, or else its own or its image's alt text, or its title,
+// whitespace collapsed
+func elementText(e *colly.HTMLElement) string {
+	text := strings.Join(strings.Fields(e.Text), " ")
+	if text == "" {
+		text = e.Attr("alt")
+	}
+	if text == "" {
+		// An image link reads as its image's alt text
+		text, _ = e.DOM.Find("img[alt]").First().Attr("alt")
+	}
+	if text == "" {
+		text = e.Attr("title")
+	}
+	return truncateText(strings.TrimSpace(text))
+}
+
+// truncateText shortens text to maxLinkText characters
+func truncateText(text string) string {
+	if runes := []rune(text); len(runes) > maxLinkText {
+		return string(runes[:maxLinkText-1]) + "…"
+	}
+	return text
+}
+
+// extractLinks turns an attribute value into the raw links it contains
+func extractLinks(kind types.ExtractorKind, value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	switch kind {
+	case types.ExtractSrcset:
+		return parseSrcset(value)
+	case types.ExtractRefresh:
+		if link := parseRefresh(value); link != "" {
+			return []string{link}
+		}
+		return nil
+	default:
+		return []string{value}
+	}
+}
+
+// isHTTPLink reports whether an absolute link is an http or https URL
+func isHTTPLink(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// parseSrcset returns the URLs of a srcset candidate list such as
+// "a.png 1x, b.png 2x", following the HTML parsing rules: a URL runs to the
+// next whitespace and may itself contain commas, and a comma only separates
+// candidates after a URL's descriptors, outside parentheses
+func parseSrcset(value string) []string {
+	isSpace := func(b byte) bool { return strings.IndexByte(" \t\n\f\r", b) >= 0 }
+
+	var links []string
+	for i := 0; i < len(value); {
+		// Skip separators before a candidate
+		for i < len(value) && (isSpace(value[i]) || value[i] == ',') {
+			i++
+		}
+		start := i
+		for i < len(value) && !isSpace(value[i]) {
+			i++
+		}
+		link := value[start:i]
+
+		// A URL ending in commas has no descriptors; otherwise skip them,
+		// up to the comma that ends the candidate
+		if trimmed := strings.TrimRight(link, ","); trimmed != link {
+			link = trimmed
+		} else {
+			depth := 0
+			for ; i < len(value); i++ {
+				switch value[i] {
+				case '(':
+					depth++
+				case ')':
+					if depth > 0 {
+						depth--
+					}
+				}
+				if value[i] == ',' && depth == 0 {
+					break
+				}
+			}
+		}
+
+		if link != "" && !strings.HasPrefix(link, "data:") {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// parseRefresh returns the target of a meta refresh content value such as
+// "5; url=/new-page"
+func parseRefresh(value string) string {
+	_, rest, found := strings.Cut(value, ";")
+	if !found {
+		_, rest, found = strings.Cut(value, ",")
+		if !found {
+			return ""
+		}
+	}
+
+	rest = strings.TrimSpace(rest)
+	if len(rest) >= 4 && strings.EqualFold(rest[:4], "url=") {
+		rest = strings.TrimSpace(rest[4:])
+	}
+	return strings.Trim(rest, `"'`)
+}
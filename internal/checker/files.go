@@ -0,0 +1,232 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// sourceExtensions are the file types the files mode reads
+var sourceExtensions = map[string]bool{
+	".md":  true,
+	".mdx": true,
+	".rst": true,
+}
+
+// checkFiles checks the links of every source file in paths, which may be
+// files or directories to search
+func (c *Checker) checkFiles(ctx context.Context, paths []string) error {
+	files, err := collectSourceFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	var links []foundLink
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		for _, l := range parseSource(file, content) {
+			link, ok := resolveSourceLink(file, l.target)
+			if !ok {
+				continue
+			}
+			link.src = linkSource{
+				foundOn:  file,
+				location: &types.SourceLocation{File: file, Line: l.line, Column: l.column},
+				text:     truncateText(l.text),
+			}
+			links = append(links, link)
+		}
+	}
+
+	return c.checkConcurrently(ctx, links)
+}
+
+// collectSourceFiles expands paths into the Markdown and reStructuredText
+// files they name or contain, skipping hidden and node_modules directories
+func collectSourceFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if !sourceExtensions[strings.ToLower(filepath.Ext(p))] {
+				return nil, fmt.Errorf("unsupported file %s: expected .md, .mdx or .rst", p)
+			}
+			files = append(files, p)
+			continue
+		}
+
+		err = filepath.WalkDir(p, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				base := d.Name()
+				if name != p && (strings.HasPrefix(base, ".") || base == "node_modules") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if sourceExtensions[strings.ToLower(filepath.Ext(name))] {
+				files = append(files, name)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// resolveSourceLink decides how a link written in file is checked. Remote
+// links are checked over HTTP, relative ones on disk. Root-relative links
+// are reported as skipped. Links with other schemes, such as mailto:, are
+// not checked.
+func resolveSourceLink(file, target string) (foundLink, bool) {
+	if strings.HasPrefix(target, "//") {
+		target = "https:" + target
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		// Let the HTTP check report the malformed URL
+		return foundLink{url: target}, true
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return foundLink{url: target}, true
+	case "":
+	default:
+		return foundLink{}, false
+	}
+
+	// Root-relative links depend on where the site is served from, so they
+	// cannot be resolved to a file
+	if strings.HasPrefix(u.Path, "/") {
+		return foundLink{url: target, skip: "not checked: root-relative links depend on where the site is served from"}, true
+	}
+
+	path := file
+	if u.Path != "" {
+		path = filepath.Join(filepath.Dir(file), filepath.FromSlash(u.Path))
+	}
+	link := foundLink{
+		url:      filepath.ToSlash(path),
+		file:     path,
+		fragment: u.Fragment,
+	}
+	if u.Fragment != "" {
+		link.url += "#" + u.Fragment
+	}
+	return link, true
+}
+
+// checkLocalFile checks a link to a file on disk and, if set, its fragment.
+// A link with a skip reason is reported as skipped.
+func (c *Checker) checkLocalFile(link foundLink) types.LinkResult {
+	c.addReferrer(link.url, link.src)
+
+	c.mu.Lock()
+	if c.visited[link.url] {
+		c.mu.Unlock()
+		return types.LinkResult{URL: link.url, Status: types.StatusSkipped}
+	}
+	c.visited[link.url] = true
+	c.mu.Unlock()
+
+	skip := link.skip != "" || c.shouldIgnore(link.url) || !c.inCheckScope(link.url)
+	if !skip && !c.budget.allowLink() {
+		return types.LinkResult{URL: link.url, Status: types.StatusSkipped}
+	}
+
+	result := types.LinkResult{
+		URL:      link.url,
+		FoundOn:  link.src.foundOn,
+		Location: link.src.location,
+		Scope:    types.ScopeInternal,
+	}
+
+	switch {
+	case skip:
+		result.Status = types.StatusSkipped
+		result.Error = link.skip
+	case !exists(link.file):
+		result.Status = types.StatusDead
+		result.Error = "missing file " + filepath.ToSlash(link.file)
+	case link.fragment != "" && c.config.CheckFragments && !c.fileHasAnchor(link.file, link.fragment):
+		result.Status = types.StatusMissingFragment
+		result.Error = fmt.Sprintf("fragment #%s not found", link.fragment)
+	default:
+		result.Status = types.StatusOK
+	}
+
+	result.CheckedAt = time.Now()
+	c.addResult(result)
+	if result.Status != types.StatusSkipped {
+		c.notifyProgress(link.url, result.Status)
+	}
+	return result
+}
+
+// fileHasAnchor reports whether a local file defines the fragment. Files
+// whose anchors cannot be read are given the benefit of the doubt.
+func (c *Checker) fileHasAnchor(file, fragment string) bool {
+	page := c.anchors.page("file:" + filepath.ToSlash(file))
+	page.once.Do(func() {
+		page.anchors = fileAnchors(file)
+	})
+	if page.anchors == nil {
+		return true
+	}
+
+	if page.anchors[fragment] {
+		return true
+	}
+	decoded, err := url.PathUnescape(fragment)
+	return err == nil && page.anchors[decoded]
+}
+
+// fileAnchors returns the fragment targets of a Markdown or HTML file, or
+// nil for other file types
+func fileAnchors(file string) map[string]bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	if !sourceExtensions[ext] && ext != ".html" && ext != ".htm" {
+		return nil
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	switch ext {
+	case ".md", ".mdx":
+		return markdownAnchors(string(content))
+	case ".html", ".htm":
+		return parseAnchors(bytes.NewReader(content))
+	default:
+		return nil
+	}
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
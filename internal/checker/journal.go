@@ -0,0 +1,247 @@
+package checker
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// depthOffsetKey is the colly context key holding how deep a resumed crawl
+// root really is; colly itself restarts every root at depth 1
+const depthOffsetKey = "unlinked_depth_offset"
+
+// Journal entry types
+const (
+	entryStart       = "start"        // seeds of the crawl
+	entryQueued      = "queued"       // a page was added to the crawl frontier
+	entryCrawled     = "crawled"      // a page's links have all been checked and queued
+	entryResult      = "result"       // a link finished checking
+	entryReferrer    = "referrer"     // a link was found on a page
+	entryNotFollowed = "not_followed" // a crawl policy kept a link from being followed
+)
+
+// journalEntry is one line of the crawl journal
+type journalEntry struct {
+	Type     string               `json:"type"`
+	Seeds    []string             `json:"seeds,omitempty"`
+	URL      string               `json:"url,omitempty"`
+	Depth    int                  `json:"depth,omitempty"`
+	Page     bool                 `json:"page,omitempty"` // a crawled URL was an HTML page
+	Result   *types.LinkResult    `json:"result,omitempty"`
+	Referrer *types.Referrer      `json:"referrer,omitempty"`
+	Reason   types.NoFollowReason `json:"reason,omitempty"`
+}
+
+// journal appends crawl progress to a JSON Lines file so an interrupted
+// crawl can be resumed. A nil journal records nothing.
+type journal struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+	err error // first failed write; later entries are dropped
+}
+
+// pendingPage is a frontier page that was queued but never crawled
+type pendingPage struct {
+	url   string
+	depth int
+}
+
+// StartJournal records the crawl of seeds to a new journal at path,
+// replacing any previous journal there
+func (c *Checker) StartJournal(path string, seeds []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create state file: %w", err)
+	}
+
+	c.journal = &journal{f: f, enc: json.NewEncoder(f)}
+	c.journal.write(journalEntry{Type: entryStart, Seeds: seeds})
+	if err := c.journal.err; err != nil {
+		f.Close()
+		c.journal = nil
+		return err
+	}
+	return nil
+}
+
+// ResumeJournal restores the visited set, finished results and frontier of
+// an interrupted crawl from the journal at path, then keeps appending to it.
+// It returns the seeds the crawl was started with.
+func (c *Checker) ResumeJournal(path string) ([]string, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state file: %w", err)
+	}
+
+	seeds, err := c.replayJournal(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+
+	if err := seekToLineEnd(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+	c.journal = &journal{f: f, enc: json.NewEncoder(f)}
+	return seeds, nil
+}
+
+// seekToLineEnd moves to the end of f, terminating a partially written last
+// line so that new entries start on a line of their own
+func seekToLineEnd(f *os.File) error {
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil || size == 0 {
+		return err
+	}
+
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, size-1); err != nil {
+		return err
+	}
+	if last[0] != '\n' {
+		_, err = f.Write([]byte{'\n'})
+	}
+	return err
+}
+
+// Close flushes and closes the journal, if any. It returns the first error
+// writing to the journal, as a journal missing entries cannot be resumed
+// from. Closing again does nothing.
+func (c *Checker) Close() error {
+	if c.journal == nil {
+		return nil
+	}
+	c.journal.mu.Lock()
+	defer c.journal.mu.Unlock()
+	if c.journal.f == nil {
+		return nil
+	}
+	err := c.journal.f.Close()
+	c.journal.f = nil
+	if c.journal.err != nil {
+		return c.journal.err
+	}
+	return err
+}
+
+// replayJournal loads journal entries into the checker
+func (c *Checker) replayJournal(r io.Reader) ([]string, error) {
+	var (
+		seeds  []string
+		queued []pendingPage
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	var badLine error
+	for scanner.Scan() {
+		line++
+		// Only the last line may be damaged, by a crash mid-write
+		if badLine != nil {
+			return nil, badLine
+		}
+
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			badLine = fmt.Errorf("line %d: %w", line, err)
+			continue
+		}
+
+		switch entry.Type {
+		case entryStart:
+			seeds = entry.Seeds
+		case entryQueued:
+			queued = append(queued, pendingPage{url: entry.URL, depth: entry.Depth})
+			c.followed[c.pageKey(entry.URL)] = true
+			delete(c.notFollowed, c.pageKey(entry.URL))
+		case entryNotFollowed:
+			if !c.followed[entry.URL] {
+				c.notFollowed[entry.URL] = entry.Reason
+			}
+		case entryCrawled:
+			c.crawled[c.pageKey(entry.URL)] = true
+			if entry.Page {
+				c.sitemaps.markReached(c.pageKey(entry.URL))
+			}
+		case entryReferrer:
+			if entry.Referrer != nil {
+				c.appendReferrer(entry.URL, *entry.Referrer)
+			}
+		case entryResult:
+			if entry.Result != nil {
+				c.results = append(c.results, *entry.Result)
+				c.visited[c.normalize(entry.Result.URL)] = true
+				if entry.Result.FoundOn != "" {
+					c.sitemaps.markLinked(c.normalize(entry.Result.URL))
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if seeds == nil {
+		return nil, errors.New("no crawl start recorded")
+	}
+
+	for _, page := range queued {
+		if !c.crawled[c.pageKey(page.url)] {
+			c.pending = append(c.pending, page)
+		}
+	}
+	return seeds, nil
+}
+
+// write appends an entry to the journal
+func (j *journal) write(entry journalEntry) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.err != nil || j.f == nil {
+		return
+	}
+	if err := j.enc.Encode(entry); err != nil {
+		j.err = fmt.Errorf("failed to write state file: %w", err)
+	}
+}
+
+func (j *journal) queued(url string, depth int) {
+	j.write(journalEntry{Type: entryQueued, URL: url, Depth: depth})
+}
+
+func (j *journal) crawled(url string, page bool) {
+	j.write(journalEntry{Type: entryCrawled, URL: url, Page: page})
+}
+
+func (j *journal) result(result types.LinkResult) {
+	j.write(journalEntry{Type: entryResult, Result: &result})
+}
+
+func (j *journal) referrer(url string, ref types.Referrer) {
+	j.write(journalEntry{Type: entryReferrer, URL: url, Referrer: &ref})
+}
+
+func (j *journal) notFollowed(url string, reason types.NoFollowReason) {
+	j.write(journalEntry{Type: entryNotFollowed, URL: url, Reason: reason})
+}
+
+// pageDepth returns the real crawl depth of a request, accounting for
+// roots restarted by a resume
+func pageDepth(r *colly.Request) int {
+	if offset, ok := r.Ctx.GetAny(depthOffsetKey).(int); ok {
+		return r.Depth + offset
+	}
+	return r.Depth
+}
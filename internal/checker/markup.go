@@ -0,0 +1,376 @@
+package checker
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// sourceLink is a link found in a source file
+type sourceLink struct {
+	target string
+	text   string // link text, when the syntax has one
+	line   int    // 1-based
+	column int    // 1-based, in characters
+}
+
+// span is the byte range of a link target within a line, with the link's
+// text when the syntax has one
+type span struct {
+	start, end int
+	text       string
+}
+
+var (
+	// Markdown
+	mdFence       = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	mdReference   = regexp.MustCompile(`^ {0,3}\[[^\]^][^\]]*\]:[ \t]*(<[^>]*>|\S+)`)
+	mdAutolink    = regexp.MustCompile(`<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^<>\s]*)>`)
+	mdHTMLAttr    = regexp.MustCompile(`\b(?:href|src)\s*=\s*["']([^"']+)["']`)
+	mdATXHeading  = regexp.MustCompile(`^ {0,3}#{1,6}[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	mdSetext      = regexp.MustCompile(`^ {0,3}(?:=+|-+)[ \t]*$`)
+	mdHeadingID   = regexp.MustCompile(`[ \t]*\{#([^}\s]+)\}$`)
+	mdHTMLAnchor  = regexp.MustCompile(`\b(?:id|name)\s*=\s*["']([^"']+)["']`)
+	mdInlineLink  = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdInlineMarks = regexp.MustCompile("[`*~]")
+
+	// reStructuredText
+	rstDirective = regexp.MustCompile(`^\s*\.\.\s+([a-zA-Z-]+)::[ \t]*(.*)$`)
+	rstTarget    = regexp.MustCompile(`^\s*\.\.\s+_[^:]+:[ \t]+(\S+)`)
+	rstInline    = regexp.MustCompile("`([^`]*?)\\s*<([^<>`]+)>`_{1,2}")
+
+	// Both
+	bareURL = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `\])]+`)
+)
+
+// parseSource returns the links in a Markdown or reStructuredText file
+func parseSource(name string, content []byte) []sourceLink {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".rst":
+		return parseRST(string(content))
+	default:
+		return parseMarkdown(string(content))
+	}
+}
+
+// parseMarkdown returns the inline, image, reference-style, autolink and
+// HTML attribute links of a Markdown or MDX document, outside code
+func parseMarkdown(content string) []sourceLink {
+	var links []sourceLink
+	fence := ""
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+
+		if m := mdFence.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+				continue
+			case m[1][0] == fence[0] && len(m[1]) >= len(fence):
+				fence = ""
+				continue
+			}
+		}
+		if fence != "" {
+			continue
+		}
+
+		line = maskCodeSpans(line)
+		var found []span
+
+		// A reference definition takes the whole line
+		if m := mdReference.FindStringSubmatchIndex(line); m != nil {
+			found = append(found, trimAngles(line, span{start: m[2], end: m[3]}))
+		} else {
+			found = append(found, inlineDestinations(line)...)
+			for _, m := range mdAutolink.FindAllStringSubmatchIndex(line, -1) {
+				found = appendUnlessOverlapping(found, span{start: m[2], end: m[3]})
+			}
+			for _, m := range mdHTMLAttr.FindAllStringSubmatchIndex(line, -1) {
+				found = appendUnlessOverlapping(found, span{start: m[2], end: m[3]})
+			}
+		}
+		found = appendBareURLs(found, line)
+
+		links = append(links, toSourceLinks(line, i+1, found)...)
+	}
+	return links
+}
+
+// inlineDestinations returns the destinations of [text](dest) links and
+// ![alt](dest) images on a line
+func inlineDestinations(line string) []span {
+	var found []span
+	for offset := 0; ; {
+		idx := strings.Index(line[offset:], "](")
+		if idx < 0 {
+			return found
+		}
+		start := offset + idx + 2
+		offset = start
+		text := bracketText(line, offset-2)
+
+		// Skip leading whitespace
+		for start < len(line) && (line[start] == ' ' || line[start] == '\t') {
+			start++
+		}
+		if start >= len(line) {
+			return found
+		}
+
+		// <dest> may contain spaces; a bare dest ends at whitespace or an
+		// unbalanced closing parenthesis
+		if line[start] == '<' {
+			if end := strings.IndexByte(line[start:], '>'); end > 0 {
+				found = append(found, span{start + 1, start + end, text})
+			}
+			continue
+		}
+		end, depth := start, 0
+	scan:
+		for ; end < len(line); end++ {
+			switch line[end] {
+			case ' ', '\t':
+				break scan
+			case '(':
+				depth++
+			case ')':
+				if depth == 0 {
+					break scan
+				}
+				depth--
+			}
+		}
+		if end > start {
+			found = append(found, span{start, end, text})
+		}
+	}
+}
+
+// bracketText returns the text of the [bracketed] link text that closes at
+// index end, allowing nested brackets such as an image inside a link
+func bracketText(line string, end int) string {
+	depth := 0
+	for i := end - 1; i >= 0; i-- {
+		switch line[i] {
+		case ']':
+			depth++
+		case '[':
+			if depth == 0 {
+				return line[i+1 : end]
+			}
+			depth--
+		}
+	}
+	return ""
+}
+
+// parseRST returns the inline hyperlinks, hyperlink targets, image, figure
+// and include directives and standalone URLs of a reStructuredText
+// document, outside literal blocks
+func parseRST(content string) []sourceLink {
+	var links []sourceLink
+	literal := false
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+
+		// Literal blocks last while lines are blank or indented
+		if literal {
+			if strings.TrimSpace(line) == "" || line[0] == ' ' || line[0] == '\t' {
+				continue
+			}
+			literal = false
+		}
+
+		var found []span
+		if m := rstDirective.FindStringSubmatchIndex(line); m != nil {
+			switch strings.ToLower(line[m[2]:m[3]]) {
+			case "image", "figure", "include", "literalinclude":
+				if m[5] > m[4] {
+					found = append(found, span{start: m[4], end: m[5]})
+				}
+			case "code", "code-block", "sourcecode":
+				literal = true
+				continue
+			}
+		} else if m := rstTarget.FindStringSubmatchIndex(line); m != nil {
+			// A target ending in _ refers to another target, not a URL
+			if !strings.HasSuffix(line[m[2]:m[3]], "_") {
+				found = append(found, span{start: m[2], end: m[3]})
+			}
+		} else {
+			for _, m := range rstInline.FindAllStringSubmatchIndex(line, -1) {
+				if !strings.HasSuffix(line[m[4]:m[5]], "_") {
+					found = append(found, span{m[4], m[5], line[m[2]:m[3]]})
+				}
+			}
+			if strings.HasSuffix(strings.TrimSpace(line), "::") {
+				literal = true
+			}
+		}
+		found = appendBareURLs(found, line)
+
+		links = append(links, toSourceLinks(line, i+1, found)...)
+	}
+	return links
+}
+
+// appendBareURLs adds the plain http(s) URLs of a line that are not part of
+// a link already found
+func appendBareURLs(found []span, line string) []span {
+	for _, m := range bareURL.FindAllStringIndex(line, -1) {
+		s := span{start: m[0], end: m[1]}
+		// Trailing punctuation ends the sentence, not the URL
+		for s.end > s.start && strings.ContainsRune(".,:;!?*_", rune(line[s.end-1])) {
+			s.end--
+		}
+		found = appendUnlessOverlapping(found, s)
+	}
+	return found
+}
+
+func appendUnlessOverlapping(found []span, s span) []span {
+	for _, f := range found {
+		if s.start < f.end && f.start < s.end {
+			return found
+		}
+	}
+	return append(found, s)
+}
+
+// trimAngles drops the angle brackets around a <destination>
+func trimAngles(line string, s span) span {
+	if s.end-s.start >= 2 && line[s.start] == '<' && line[s.end-1] == '>' {
+		return span{start: s.start + 1, end: s.end - 1}
+	}
+	return s
+}
+
+// toSourceLinks converts the spans found on a line to links, in column order
+func toSourceLinks(line string, lineNo int, found []span) []sourceLink {
+	sort.Slice(found, func(i, j int) bool { return found[i].start < found[j].start })
+
+	links := make([]sourceLink, 0, len(found))
+	for _, s := range found {
+		target := strings.TrimSpace(line[s.start:s.end])
+		if target == "" {
+			continue
+		}
+		links = append(links, sourceLink{
+			target: target,
+			text:   strings.TrimSpace(s.text),
+			line:   lineNo,
+			column: utf8.RuneCountInString(line[:s.start]) + 1,
+		})
+	}
+	return links
+}
+
+// maskCodeSpans blanks out `code spans` so links inside them are ignored,
+// keeping byte offsets intact
+func maskCodeSpans(line string) string {
+	if !strings.Contains(line, "`") {
+		return line
+	}
+
+	b := []byte(line)
+	for i := 0; i < len(b); {
+		if b[i] != '`' {
+			i++
+			continue
+		}
+		run := i
+		for run < len(b) && b[run] == '`' {
+			run++
+		}
+		ticks := string(b[i:run])
+		end := strings.Index(string(b[run:]), ticks)
+		if end < 0 {
+			i = run
+			continue
+		}
+		for j := i; j < run+end+len(ticks); j++ {
+			b[j] = ' '
+		}
+		i = run + end + len(ticks)
+	}
+	return string(b)
+}
+
+// markdownAnchors returns the fragment targets of a Markdown document: the
+// GitHub-style slugs of its headings, {#custom} heading ids and HTML id and
+// name attributes
+func markdownAnchors(content string) map[string]bool {
+	anchors := make(map[string]bool)
+	counts := make(map[string]int)
+	addHeading := func(text string) {
+		if m := mdHeadingID.FindStringSubmatch(text); m != nil {
+			anchors[m[1]] = true
+			return
+		}
+		slug := slugify(text)
+		if n := counts[slug]; n > 0 {
+			anchors[slug+"-"+strconv.Itoa(n)] = true
+		} else {
+			anchors[slug] = true
+		}
+		counts[slug]++
+	}
+
+	lines := strings.Split(content, "\n")
+	fence := ""
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+
+		if m := mdFence.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+				continue
+			case m[1][0] == fence[0] && len(m[1]) >= len(fence):
+				fence = ""
+				continue
+			}
+		}
+		if fence != "" {
+			continue
+		}
+
+		for _, m := range mdHTMLAnchor.FindAllStringSubmatch(line, -1) {
+			anchors[m[1]] = true
+		}
+
+		switch {
+		case mdATXHeading.MatchString(line):
+			addHeading(mdATXHeading.FindStringSubmatch(line)[1])
+		case strings.TrimSpace(line) != "" && !mdSetext.MatchString(line) &&
+			i+1 < len(lines) && mdSetext.MatchString(strings.TrimSuffix(lines[i+1], "\r")):
+			addHeading(strings.TrimSpace(line))
+		}
+	}
+	return anchors
+}
+
+// slugify builds a heading anchor the way GitHub does: link and emphasis
+// markup dropped, lowercased, punctuation removed and spaces turned into dashes
+func slugify(text string) string {
+	text = mdInlineLink.ReplaceAllString(text, "$1")
+	text = mdInlineMarks.ReplaceAllString(text, "")
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
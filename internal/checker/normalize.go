@@ -0,0 +1,168 @@
+package checker
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// normalizer turns URLs into the canonical form used to tell whether two
+// links point at the same resource
+type normalizer struct {
+	stripParams   []string // query parameter name globs
+	sortParams    bool
+	trailingSlash types.TrailingSlash
+	stripFragment bool
+}
+
+func newNormalizer(cfg types.NormalizeConfig) (*normalizer, error) {
+	for _, pattern := range cfg.StripParams {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid strip_params pattern %q: %w", pattern, err)
+		}
+	}
+
+	switch cfg.TrailingSlash {
+	case "", types.TrailingSlashKeep, types.TrailingSlashAdd, types.TrailingSlashStrip:
+	default:
+		return nil, fmt.Errorf("invalid trailing_slash %q: expected keep, add or strip", cfg.TrailingSlash)
+	}
+
+	return &normalizer{
+		stripParams:   cfg.StripParams,
+		sortParams:    cfg.SortParams,
+		trailingSlash: cfg.TrailingSlash,
+		stripFragment: cfg.StripFragment,
+	}, nil
+}
+
+// normalize returns the canonical form of an http(s) URL: scheme and host
+// lowercased, default port and dot segments removed, then the configured
+// query, trailing slash and fragment rules applied. Other URLs, and those
+// that do not parse, are returned unchanged.
+func (n *normalizer) normalize(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return rawURL
+	}
+
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = strings.TrimSuffix(u.Host, ":"+port)
+	}
+
+	p := removeDotSegments(u.EscapedPath())
+	if p == "" {
+		p = "/"
+	}
+	switch n.trailingSlash {
+	case types.TrailingSlashAdd:
+		// Only directory-like paths; /style.css stays a file
+		if !strings.HasSuffix(p, "/") && !strings.Contains(path.Base(p), ".") {
+			p += "/"
+		}
+	case types.TrailingSlashStrip:
+		if p != "/" {
+			p = strings.TrimSuffix(p, "/")
+		}
+	}
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		u.Path, u.RawPath = unescaped, p
+	}
+
+	u.RawQuery = n.normalizeQuery(u.RawQuery)
+	u.ForceQuery = false
+
+	if n.stripFragment {
+		u.Fragment, u.RawFragment = "", ""
+	}
+	return u.String()
+}
+
+// normalizeQuery drops the parameters matching strip_params and, if
+// configured, orders the rest by name. Values keep their original encoding.
+func (n *normalizer) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" || (len(n.stripParams) == 0 && !n.sortParams) {
+		return rawQuery
+	}
+
+	params := strings.Split(rawQuery, "&")
+	kept := params[:0]
+	for _, param := range params {
+		if param == "" || n.stripped(paramName(param)) {
+			continue
+		}
+		kept = append(kept, param)
+	}
+	if n.sortParams {
+		sort.SliceStable(kept, func(i, j int) bool { return paramName(kept[i]) < paramName(kept[j]) })
+	}
+	return strings.Join(kept, "&")
+}
+
+// stripped reports whether a query parameter is removed by strip_params
+func (n *normalizer) stripped(name string) bool {
+	for _, pattern := range n.stripParams {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// paramName returns the decoded name of a name=value query parameter
+func paramName(param string) string {
+	name, _, _ := strings.Cut(param, "=")
+	if unescaped, err := url.QueryUnescape(name); err == nil {
+		return unescaped
+	}
+	return name
+}
+
+// removeDotSegments resolves "." and ".." segments in a URL path as
+// described in RFC 3986 section 5.2.4, keeping a trailing slash
+func removeDotSegments(p string) string {
+	if !strings.Contains(p, ".") {
+		return p
+	}
+
+	segments := strings.Split(p, "/")
+	out := make([]string, 0, len(segments))
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		switch seg {
+		case ".":
+		case "..":
+			if len(out) > 1 {
+				out = out[:len(out)-1]
+			}
+		default:
+			out = append(out, seg)
+			continue
+		}
+		// A path ending in a dot segment refers to a directory
+		if last {
+			out = append(out, "")
+		}
+	}
+	return strings.Join(out, "/")
+}
+
+// normalize returns the canonical form of rawURL
+func (c *Checker) normalize(rawURL string) string {
+	return c.normalizer.normalize(rawURL)
+}
+
+// pageKey returns the key crawled pages are told apart by: the canonical form
+// of page without its fragment, which names a place on the page, not a page.
+// In directory mode index files share the key of their directory.
+func (c *Checker) pageKey(page string) string {
+	if c.site != nil {
+		page = c.site.indexPage(page)
+	}
+	return pageKeyString(c.normalize(page))
+}
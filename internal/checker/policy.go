@@ -0,0 +1,145 @@
+package checker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// pagePolicy holds the crawl signals of a page that apply to all its links
+type pagePolicy struct {
+	nofollow  bool   // robots meta tag or X-Robots-Tag header says nofollow
+	canonical string // rel=canonical URL, when it is another page
+}
+
+// registerPolicies reads the crawl signals of every page before its links
+// are extracted. It must be registered before the extractors: colly runs
+// HTML callbacks in registration order.
+func (c *Checker) registerPolicies(ctx context.Context, collector *colly.Collector) {
+	follow := c.config.Follow
+	if !follow.RespectNofollow && !follow.RespectCanonical {
+		return
+	}
+
+	collector.OnHTML("html", func(e *colly.HTMLElement) {
+		page := e.Request.URL.String()
+		var policy pagePolicy
+
+		if follow.RespectNofollow {
+			policy.nofollow = robotsNofollow(e.Response.Headers.Values("X-Robots-Tag"))
+			e.ForEach("meta[name]", func(_ int, meta *colly.HTMLElement) {
+				if strings.EqualFold(meta.Attr("name"), "robots") && robotsNofollow([]string{meta.Attr("content")}) {
+					policy.nofollow = true
+				}
+			})
+		}
+
+		// A canonical URL outside the crawl, such as the production site of a
+		// build checked from disk, says nothing about duplicates
+		if follow.RespectCanonical {
+			if href := strings.TrimSpace(e.ChildAttr(`link[rel~="canonical"]`, "href")); href != "" {
+				canonical := e.Request.AbsoluteURL(href)
+				if canonical != "" && c.pageKey(canonical) != c.pageKey(page) && c.shouldFollow(canonical) {
+					policy.canonical = canonical
+					c.allowFollow(c.pageKey(canonical))
+					if c.robotsAllowed(ctx, canonical) {
+						c.visit(e.Request, canonical)
+					}
+				}
+			}
+		}
+
+		if policy.nofollow || policy.canonical != "" {
+			c.mu.Lock()
+			c.policies[page] = policy
+			c.mu.Unlock()
+		}
+	})
+
+	collector.OnScraped(func(r *colly.Response) {
+		c.mu.Lock()
+		delete(c.policies, r.Request.URL.String())
+		c.mu.Unlock()
+	})
+}
+
+// noFollowReason returns the policy that keeps a crawl from following the
+// link in e, if any
+func (c *Checker) noFollowReason(e *colly.HTMLElement) types.NoFollowReason {
+	follow := c.config.Follow
+	if follow.RespectNofollow && hasToken(e.Attr("rel"), "nofollow") {
+		return types.NoFollowRel
+	}
+
+	c.mu.Lock()
+	policy := c.policies[e.Request.URL.String()]
+	c.mu.Unlock()
+	switch {
+	case policy.nofollow:
+		return types.NoFollowRobots
+	case policy.canonical != "":
+		return types.NoFollowCanonical
+	}
+	return ""
+}
+
+// suppressFollow records that a policy kept link from being followed. Only
+// links that are never followed are reported, with the first reason found.
+func (c *Checker) suppressFollow(link string, reason types.NoFollowReason) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.followed[link] || c.notFollowed[link] != "" {
+		return
+	}
+	c.notFollowed[link] = reason
+	c.journal.notFollowed(link, reason)
+}
+
+// allowFollow records that link is followed, clearing any suppression
+func (c *Checker) allowFollow(link string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.followed[link] = true
+	delete(c.notFollowed, link)
+}
+
+// valuedDirectives are the robots directives written as "name: value"
+var valuedDirectives = map[string]bool{
+	"unavailable_after": true,
+	"max-snippet":       true,
+	"max-image-preview": true,
+	"max-video-preview": true,
+}
+
+// robotsNofollow reports whether robots directives, such as the content of
+// a robots meta tag or X-Robots-Tag headers, include nofollow or none.
+// Directives scoped to a named crawler, as in "googlebot: nofollow", do not
+// apply.
+func robotsNofollow(values []string) bool {
+	for _, value := range values {
+		directives := strings.Split(value, ",")
+		if name, _, ok := strings.Cut(directives[0], ":"); ok && !valuedDirectives[strings.ToLower(strings.TrimSpace(name))] {
+			continue
+		}
+		for _, directive := range directives {
+			switch strings.ToLower(strings.TrimSpace(directive)) {
+			case "nofollow", "none":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasToken reports whether a space-separated attribute value such as rel
+// contains token
+func hasToken(value, token string) bool {
+	for _, field := range strings.Fields(value) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// defaultMaxRedirects is used when max_redirects is unset
+const defaultMaxRedirects = 10
+
+var (
+	errRedirectLoop     = errors.New("redirect loop")
+	errTooManyRedirects = errors.New("too many redirects")
+)
+
+// checkRedirect is the client's redirect policy. It stops on loops and on
+// chains longer than max_redirects, and never follows when follow_redirects is off.
+func (c *Checker) checkRedirect(req *http.Request, via []*http.Request) error {
+	if !c.config.FollowRedirects {
+		return http.ErrUseLastResponse
+	}
+
+	next := req.URL.String()
+	for _, prev := range via {
+		if prev.URL.String() == next {
+			return fmt.Errorf("%w back to %s", errRedirectLoop, next)
+		}
+	}
+
+	limit := c.config.MaxRedirects
+	if limit < 1 {
+		limit = defaultMaxRedirects
+	}
+	if len(via) > limit {
+		return fmt.Errorf("%w: stopped after %d", errTooManyRedirects, limit)
+	}
+
+	return nil
+}
+
+// redirectChain reconstructs the redirect hops that led to resp. When resp is
+// itself a redirect (not followed, or stopped by checkRedirect) it is the last hop.
+func redirectChain(resp *http.Response) []types.RedirectHop {
+	if resp == nil || resp.Request == nil {
+		return nil
+	}
+
+	var hops []types.RedirectHop
+	if isRedirect(resp.StatusCode) {
+		hops = append(hops, types.RedirectHop{URL: resp.Request.URL.String(), StatusCode: resp.StatusCode})
+	}
+	for prev := resp.Request.Response; prev != nil && prev.Request != nil; prev = prev.Request.Response {
+		hops = append(hops, types.RedirectHop{URL: prev.Request.URL.String(), StatusCode: prev.StatusCode})
+	}
+
+	slices.Reverse(hops)
+	return hops
+}
+
+// redirectTarget returns where a chain ends: the final URL requested, or the
+// Location of a redirect that was not followed
+func redirectTarget(resp *http.Response) string {
+	if isRedirect(resp.StatusCode) {
+		if loc, err := resp.Location(); err == nil {
+			return loc.String()
+		}
+		return resp.Header.Get("Location")
+	}
+	return resp.Request.URL.String()
+}
+
+// redirectType classifies a redirect status code
+func redirectType(code int) types.RedirectType {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+		return types.RedirectPermanent
+	default:
+		return types.RedirectTemporary
+	}
+}
+
+// isRedirect reports whether code is a redirect that carries a Location
+func isRedirect(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
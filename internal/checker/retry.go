@@ -0,0 +1,204 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// Error classes that can be listed in retry.retry_on_errors
+const (
+	errorClassTimeout    = "timeout"
+	errorClassConnection = "connection"
+	errorClassDNS        = "dns"
+	errorClassTLS        = "tls"
+	errorClassOther      = "other"
+)
+
+// probeResult is the outcome of probing a URL, possibly over several attempts
+type probeResult struct {
+	resp         *http.Response
+	method       string
+	err          error
+	attempts     int
+	responseTime time.Duration // duration of the final attempt only
+}
+
+// probeWithRetry probes targetURL until it gets a non-retryable outcome, the
+// retry policy gives up, or ctx is cancelled
+func (c *Checker) probeWithRetry(ctx context.Context, targetURL string) probeResult {
+	policy := c.config.Retry
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var result probeResult
+	for {
+		result.attempts++
+		startTime := time.Now()
+		result.resp, result.method, result.err = c.probe(ctx, targetURL)
+		result.responseTime = time.Since(startTime)
+
+		if result.attempts >= maxAttempts {
+			return result
+		}
+
+		wait, retry := c.retryDelay(result.attempts, result.resp, result.err)
+		if !retry {
+			return result
+		}
+
+		if result.resp != nil {
+			io.Copy(io.Discard, io.LimitReader(result.resp.Body, 4096))
+			result.resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			result.resp, result.err = nil, ctx.Err()
+			return result
+		}
+	}
+}
+
+// retryDelay decides whether an attempt should be retried and how long to wait first
+func (c *Checker) retryDelay(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	policy := c.config.Retry
+
+	if err != nil {
+		class := classifyError(err)
+		for _, retryable := range policy.RetryOnErrors {
+			if retryable == class {
+				return c.backoff(attempt), true
+			}
+		}
+		return 0, false
+	}
+
+	retryable := false
+	for _, code := range policy.RetryOnStatus {
+		if resp.StatusCode == code {
+			retryable = true
+			break
+		}
+	}
+	if !retryable {
+		return 0, false
+	}
+
+	if policy.RespectRetryAfter {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			// A server asking us to come back later than we are willing to
+			// wait gets its response reported as-is
+			if wait > time.Duration(policy.MaxRetryAfter)*time.Second {
+				return 0, false
+			}
+			return wait, true
+		}
+	}
+
+	return c.backoff(attempt), true
+}
+
+// maxRetryAfter returns the longest Retry-After pause a policy honors
+func maxRetryAfter(policy types.RetryConfig) time.Duration {
+	if !policy.RespectRetryAfter {
+		return 0
+	}
+	return time.Duration(policy.MaxRetryAfter) * time.Second
+}
+
+// backoff returns the exponential backoff with jitter before the given retry
+func (c *Checker) backoff(attempt int) time.Duration {
+	policy := c.config.Retry
+
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(policy.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if policy.MaxBackoff > 0 && delay > float64(policy.MaxBackoff) {
+		delay = float64(policy.MaxBackoff)
+	}
+
+	if jitter := math.Min(math.Max(policy.Jitter, 0), 1); jitter > 0 {
+		delay *= 1 - jitter + 2*jitter*rand.Float64()
+	}
+
+	return time.Duration(delay) * time.Millisecond
+}
+
+// parseRetryAfter parses a Retry-After header given either as seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		wait := at.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
+
+// classifyError maps a request error to one of the retryable error classes
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return errorClassDNS
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return errorClassTimeout
+	}
+
+	var (
+		certErr     *tls.CertificateVerificationError
+		recordErr   tls.RecordHeaderError
+		unknownAuth x509.UnknownAuthorityError
+		hostnameErr x509.HostnameError
+		invalidCert x509.CertificateInvalidError
+	)
+	if errors.As(err, &certErr) || errors.As(err, &recordErr) || errors.As(err, &unknownAuth) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidCert) {
+		return errorClassTLS
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errorClassConnection
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return errorClassConnection
+	}
+
+	return errorClassOther
+}
@@ -0,0 +1,107 @@
+package checker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/temoto/robotstxt"
+)
+
+// maxRobotsSize caps how much of a robots.txt file is read
+const maxRobotsSize = 512 * 1024
+
+// robotsCache fetches robots.txt once per host and answers allow/disallow
+// questions for the configured user agent
+type robotsCache struct {
+	checker *Checker
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+// robotsEntry holds the parsed robots.txt of a single scheme and host
+type robotsEntry struct {
+	once sync.Once
+	data *robotstxt.RobotsData
+}
+
+func newRobotsCache(c *Checker) *robotsCache {
+	return &robotsCache{
+		checker: c,
+		entries: make(map[string]*robotsEntry),
+	}
+}
+
+// allowed reports whether the configured user agent may fetch u
+func (r *robotsCache) allowed(ctx context.Context, u *url.URL) bool {
+	data := r.get(ctx, u)
+	if data == nil {
+		return true
+	}
+
+	path := u.EscapedPath()
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return data.TestAgent(path, r.checker.config.UserAgent)
+}
+
+// get returns the robots.txt data for u's host, fetching it on first use.
+// Hosts whose robots.txt cannot be fetched are treated as allowing everything.
+func (r *robotsCache) get(ctx context.Context, u *url.URL) *robotstxt.RobotsData {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+
+	key := u.Scheme + "://" + u.Host
+	r.mu.Lock()
+	entry, ok := r.entries[key]
+	if !ok {
+		entry = &robotsEntry{}
+		r.entries[key] = entry
+	}
+	r.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.data = r.fetch(ctx, key)
+		if entry.data == nil {
+			return
+		}
+
+		// Crawl-delay applies to every request to this host, so hand it to
+		// the scheduler rather than sleeping here
+		if group := entry.data.FindGroup(r.checker.config.UserAgent); group.CrawlDelay > 0 {
+			r.checker.scheduler.SetMinInterval(u.Hostname(), group.CrawlDelay)
+		}
+	})
+
+	return entry.data
+}
+
+// fetch downloads and parses robots.txt from origin
+func (r *robotsCache) fetch(ctx context.Context, origin string) *robotstxt.RobotsData {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("User-Agent", r.checker.config.UserAgent)
+
+	resp, err := r.checker.client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+	if err != nil {
+		return nil
+	}
+
+	data, err := robotstxt.FromStatusAndBytes(resp.StatusCode, body)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,161 @@
+package checker
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// ruleStatuses are the statuses a status rule may assign
+var ruleStatuses = map[types.LinkStatus]bool{
+	types.StatusOK:          true,
+	types.StatusDead:        true,
+	types.StatusRedirect:    true,
+	types.StatusError:       true,
+	types.StatusSkipped:     true,
+	types.StatusRateLimited: true,
+}
+
+// statusRule is a compiled types.StatusRule
+type statusRule struct {
+	name    string
+	codes   []int
+	ranges  [][2]int
+	hosts   []string
+	pattern *regexp.Regexp
+	status  types.LinkStatus
+}
+
+// compileStatusRules validates and compiles the configured status rules
+func compileStatusRules(rules []types.StatusRule) ([]statusRule, error) {
+	compiled := make([]statusRule, 0, len(rules))
+	for i, rule := range rules {
+		if !ruleStatuses[rule.Status] {
+			return nil, fmt.Errorf("status rule %d: invalid status %q", i+1, rule.Status)
+		}
+
+		sr := statusRule{
+			name:   rule.Name,
+			codes:  rule.Codes,
+			hosts:  rule.Hosts,
+			status: rule.Status,
+		}
+
+		for _, r := range rule.Ranges {
+			low, high, err := parseCodeRange(r)
+			if err != nil {
+				return nil, fmt.Errorf("status rule %d: %w", i+1, err)
+			}
+			sr.ranges = append(sr.ranges, [2]int{low, high})
+		}
+
+		if rule.Pattern != "" {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("status rule %d: invalid pattern %q: %w", i+1, rule.Pattern, err)
+			}
+			sr.pattern = re
+		}
+
+		if sr.name == "" {
+			sr.name = describeRule(rule)
+		}
+		compiled = append(compiled, sr)
+	}
+	return compiled, nil
+}
+
+// parseCodeRange parses an inclusive status code range such as "500-599"
+func parseCodeRange(value string) (int, int, error) {
+	lowText, highText, found := strings.Cut(value, "-")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid range %q, expected LOW-HIGH", value)
+	}
+
+	low, err := strconv.Atoi(strings.TrimSpace(lowText))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", value, err)
+	}
+	high, err := strconv.Atoi(strings.TrimSpace(highText))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", value, err)
+	}
+	if low > high {
+		return 0, 0, fmt.Errorf("invalid range %q: low is above high", value)
+	}
+	return low, high, nil
+}
+
+// describeRule builds a name for a rule that has none, e.g. "403 from linkedin.com -> ok"
+func describeRule(rule types.StatusRule) string {
+	var codes []string
+	for _, code := range rule.Codes {
+		codes = append(codes, strconv.Itoa(code))
+	}
+	codes = append(codes, rule.Ranges...)
+
+	var parts []string
+	if len(codes) > 0 {
+		parts = append(parts, strings.Join(codes, ","))
+	}
+	if len(rule.Hosts) > 0 {
+		parts = append(parts, "from "+strings.Join(rule.Hosts, ","))
+	}
+	if rule.Pattern != "" {
+		parts = append(parts, "matching "+rule.Pattern)
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "any")
+	}
+	return strings.Join(parts, " ") + " -> " + string(rule.Status)
+}
+
+// matches reports whether the rule applies to a response with code for targetURL
+func (r *statusRule) matches(targetURL string, code int) bool {
+	if len(r.codes) > 0 || len(r.ranges) > 0 {
+		inCodes := false
+		for _, c := range r.codes {
+			if c == code {
+				inCodes = true
+				break
+			}
+		}
+		for _, rng := range r.ranges {
+			if code >= rng[0] && code <= rng[1] {
+				inCodes = true
+				break
+			}
+		}
+		if !inCodes {
+			return false
+		}
+	}
+
+	if len(r.hosts) > 0 {
+		u, err := url.Parse(targetURL)
+		if err != nil {
+			return false
+		}
+		host := strings.ToLower(u.Hostname())
+		inHosts := false
+		for _, domain := range r.hosts {
+			if matchDomain(domain, host) {
+				inHosts = true
+				break
+			}
+		}
+		if !inHosts {
+			return false
+		}
+	}
+
+	if r.pattern != nil && !r.pattern.MatchString(targetURL) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,271 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+const (
+	// adaptiveStartInterval is the spacing imposed on an unlimited host after its first 429
+	adaptiveStartInterval = time.Second
+	// adaptiveMaxInterval caps how far a host is slowed down
+	adaptiveMaxInterval = 30 * time.Second
+)
+
+// Scheduler paces requests per host. It enforces a requests-per-second rate
+// and a cap on in-flight requests, and slows a host down when it answers 429.
+type Scheduler struct {
+	config        types.RateLimitConfig
+	maxRetryAfter time.Duration // longest Retry-After pause honored, 0 = ignore Retry-After
+	mu            sync.Mutex
+	hosts         map[string]*hostState
+}
+
+// hostState tracks pacing for a single host
+type hostState struct {
+	minInterval time.Duration // spacing from the configured rate, 0 = unlimited
+	interval    time.Duration // current spacing, grows on 429 and decays back to minInterval
+	next        time.Time     // earliest start time for the next request
+	slots       chan struct{} // in-flight semaphore, nil = unlimited
+}
+
+// NewScheduler creates a scheduler for the given rate limit configuration.
+// A 429's Retry-After holds its host for at most maxRetryAfter.
+func NewScheduler(config types.RateLimitConfig, maxRetryAfter time.Duration) *Scheduler {
+	return &Scheduler{
+		config:        config,
+		maxRetryAfter: maxRetryAfter,
+		hosts:         make(map[string]*hostState),
+	}
+}
+
+// Acquire blocks until a request to host may start
+func (s *Scheduler) Acquire(ctx context.Context, host string) error {
+	st := s.state(host)
+
+	if st.slots != nil {
+		select {
+		case st.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	start := st.next
+	if start.Before(now) {
+		start = now
+	}
+	st.next = start.Add(st.interval)
+	s.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			if st.slots != nil {
+				<-st.slots
+			}
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// free returns the in-flight slot taken by Acquire
+func (s *Scheduler) free(host string) {
+	if st := s.state(host); st.slots != nil {
+		<-st.slots
+	}
+}
+
+// adapt slows a host down after a 429 and lets it recover afterwards
+func (s *Scheduler) adapt(host string, resp *http.Response) {
+	st := s.state(host)
+	if !s.config.Adaptive || resp == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if st.interval == 0 {
+			st.interval = adaptiveStartInterval
+		} else {
+			st.interval *= 2
+		}
+		if st.interval > adaptiveMaxInterval {
+			st.interval = adaptiveMaxInterval
+		}
+
+		// Hold every request to this host until the server is ready again
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok && s.maxRetryAfter > 0 {
+			wait = min(wait, s.maxRetryAfter)
+			if resume := time.Now().Add(wait); resume.After(st.next) {
+				st.next = resume
+			}
+		}
+		return
+	}
+
+	// Recover gradually once the host stops complaining
+	if st.interval > st.minInterval {
+		st.interval -= st.interval / 10
+		if st.interval-st.minInterval < 10*time.Millisecond {
+			st.interval = st.minInterval
+		}
+	}
+}
+
+// SetMinInterval raises the minimum spacing between requests to host, e.g. to
+// honor a robots.txt Crawl-delay. It never makes a host faster than configured.
+func (s *Scheduler) SetMinInterval(host string, interval time.Duration) {
+	st := s.state(host)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if interval > st.minInterval {
+		st.minInterval = interval
+	}
+	if st.interval < st.minInterval {
+		st.interval = st.minInterval
+	}
+}
+
+// Transport wraps next so that every request goes through the scheduler
+func (s *Scheduler) Transport(next http.RoundTripper) http.RoundTripper {
+	return &scheduledTransport{scheduler: s, next: next}
+}
+
+// state returns the pacing state for host, creating it from config on first use
+func (s *Scheduler) state(host string) *hostState {
+	host = strings.ToLower(host)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if st, ok := s.hosts[host]; ok {
+		return st
+	}
+
+	rps, inFlight := s.config.RequestsPerSecond, s.config.MaxInFlight
+	for _, rule := range s.config.Hosts {
+		if matchDomain(rule.Domain, host) {
+			rps, inFlight = rule.RequestsPerSecond, rule.MaxInFlight
+			break
+		}
+	}
+
+	st := &hostState{}
+	if rps > 0 {
+		st.minInterval = time.Duration(float64(time.Second) / rps)
+		st.interval = st.minInterval
+	}
+	if inFlight > 0 {
+		st.slots = make(chan struct{}, inFlight)
+	}
+	s.hosts[host] = st
+	return st
+}
+
+// matchDomain reports whether host matches a domain glob such as "*.github.com"
+func matchDomain(pattern, host string) bool {
+	pattern = strings.ToLower(pattern)
+	if pattern == host {
+		return true
+	}
+	matched, err := path.Match(pattern, host)
+	return err == nil && matched
+}
+
+// scheduledTransport is an http.RoundTripper that paces requests through a Scheduler
+type scheduledTransport struct {
+	scheduler *Scheduler
+	next      http.RoundTripper
+}
+
+// RoundTrip holds the host's slot until the response body is closed, so
+// max_in_flight also bounds downloads
+func (t *scheduledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Hostname()
+	if err := t.scheduler.Acquire(req.Context(), host); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	t.scheduler.adapt(host, resp)
+	if err != nil {
+		t.scheduler.free(host)
+		return nil, err
+	}
+	resp.Body = &closeHookBody{ReadCloser: resp.Body, hook: func() { t.scheduler.free(host) }}
+	return resp, nil
+}
+
+// errRequestTimeout is the error of a request that ran past the configured timeout
+type errRequestTimeout struct {
+	timeout time.Duration
+}
+
+func (e *errRequestTimeout) Error() string {
+	return fmt.Sprintf("request timed out after %s", e.timeout)
+}
+
+func (e *errRequestTimeout) Timeout() bool   { return true }
+func (e *errRequestTimeout) Temporary() bool { return true }
+
+// timeoutTransport limits each request, from sending it to closing its
+// body, to a timeout. It sits inside the scheduler so that time spent
+// waiting for a rate limit, Crawl-delay or 429 back-off does not count;
+// an http.Client.Timeout would include it.
+type timeoutTransport struct {
+	timeout time.Duration
+	next    http.RoundTripper
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeoutCause(req.Context(), t.timeout, &errRequestTimeout{t.timeout})
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cause := context.Cause(ctx)
+		cancel()
+		// Report our own deadline, not an interruption of the whole check
+		if timeout, ok := cause.(*errRequestTimeout); ok && req.Context().Err() == nil {
+			return nil, timeout
+		}
+		return nil, err
+	}
+	resp.Body = &closeHookBody{ReadCloser: resp.Body, hook: cancel}
+	return resp, nil
+}
+
+// closeHookBody runs hook once when the response body is closed
+type closeHookBody struct {
+	io.ReadCloser
+	once sync.Once
+	hook func()
+}
+
+func (b *closeHookBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.hook)
+	return err
+}
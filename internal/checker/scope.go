@@ -0,0 +1,158 @@
+package checker
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// scopePattern is a compiled include or exclude pattern. Patterns starting
+// with "/" match the URL path, others the whole URL without its fragment.
+// Patterns without wildcards match as prefixes; in globs * matches any run
+// of characters, including "/", and ? matches one character.
+type scopePattern struct {
+	onPath bool
+	prefix string
+	glob   *regexp.Regexp
+}
+
+func compileScopePattern(pattern string) scopePattern {
+	p := scopePattern{onPath: strings.HasPrefix(pattern, "/")}
+	if !strings.ContainsAny(pattern, "*?") {
+		p.prefix = pattern
+		return p
+	}
+
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	p.glob = regexp.MustCompile("^" + expr + "$")
+	return p
+}
+
+func (p scopePattern) matches(u *url.URL) bool {
+	subject := u.EscapedPath()
+	if !p.onPath {
+		page := *u
+		page.Fragment, page.RawFragment = "", ""
+		subject = page.String()
+	}
+	if p.glob != nil {
+		return p.glob.MatchString(subject)
+	}
+	return strings.HasPrefix(subject, p.prefix)
+}
+
+// urlScope is compiled types.ScopeRules. It remembers the query strings seen
+// for each path to enforce max_query_variants.
+type urlScope struct {
+	include     []scopePattern
+	exclude     []scopePattern
+	maxVariants int
+
+	mu       sync.Mutex
+	variants map[string]map[string]bool // page without query -> queries seen
+}
+
+func newURLScope(rules types.ScopeRules) *urlScope {
+	s := &urlScope{
+		maxVariants: rules.MaxQueryVariants,
+		variants:    make(map[string]map[string]bool),
+	}
+	for _, pattern := range rules.Include {
+		s.include = append(s.include, compileScopePattern(pattern))
+	}
+	for _, pattern := range rules.Exclude {
+		s.exclude = append(s.exclude, compileScopePattern(pattern))
+	}
+	return s
+}
+
+// allows reports whether targetURL is in scope: not excluded, included if
+// there are include patterns, and not a query variant beyond the limit.
+// URLs that do not parse are left to the request to report.
+func (s *urlScope) allows(targetURL string) bool {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return true
+	}
+
+	for _, p := range s.exclude {
+		if p.matches(u) {
+			return false
+		}
+	}
+	if len(s.include) > 0 {
+		included := false
+		for _, p := range s.include {
+			if p.matches(u) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+	return s.withinQueryVariants(u)
+}
+
+// withinQueryVariants records u's query string for its path and reports
+// whether it is one of the first max_query_variants seen there
+func (s *urlScope) withinQueryVariants(u *url.URL) bool {
+	if s.maxVariants <= 0 || u.RawQuery == "" {
+		return true
+	}
+
+	page := *u
+	page.RawQuery, page.Fragment, page.RawFragment = "", "", ""
+	key := page.String()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := s.variants[key]
+	if seen == nil {
+		seen = make(map[string]bool)
+		s.variants[key] = seen
+	}
+	if seen[u.RawQuery] {
+		return true
+	}
+	if len(seen) >= s.maxVariants {
+		return false
+	}
+	seen[u.RawQuery] = true
+	return true
+}
+
+// shouldFollow reports whether a crawl visits link for more links: it must
+// be on an internal host, or a subdomain of one if follow.subdomains is set,
+// and within the follow rules
+func (c *Checker) shouldFollow(link string) bool {
+	if c.classify(link) != types.ScopeInternal && !(c.config.Follow.Subdomains && c.isInternalSubdomain(link)) {
+		return false
+	}
+	return c.followScope.allows(link)
+}
+
+// isInternalSubdomain reports whether targetURL's host is an internal domain
+// or below one. A www. prefix is ignored, so www.example.com also covers
+// example.com and docs.example.com.
+func (c *Checker) isInternalSubdomain(targetURL string) bool {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range c.internal {
+		domain = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(domain), "*."), "www.")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
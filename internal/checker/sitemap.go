@@ -0,0 +1,248 @@
+package checker
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gocolly/colly/v2"
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+const (
+	// maxSitemapSize caps the uncompressed size of a sitemap, as in the sitemap protocol
+	maxSitemapSize = 50 * 1024 * 1024
+	// maxSitemapFiles caps how many sitemaps are read per site, including those
+	// listed by sitemap indexes
+	maxSitemapFiles = 1000
+)
+
+// sitemapIndex collects the pages listed in sitemaps and the pages reached by
+// following links, so the two can be compared once the crawl is done
+type sitemapIndex struct {
+	mu      sync.Mutex
+	origins map[string]bool // sites whose sitemaps were already read
+	files   []string        // sitemaps read
+	pages   map[string]bool // pages listed in a sitemap
+	linked  map[string]bool // pages some crawled page links to
+	reached map[string]bool // HTML pages crawled successfully
+}
+
+func newSitemapIndex() *sitemapIndex {
+	return &sitemapIndex{
+		origins: make(map[string]bool),
+		pages:   make(map[string]bool),
+		linked:  make(map[string]bool),
+		reached: make(map[string]bool),
+	}
+}
+
+// sitemapXML matches both <urlset> sitemaps and <sitemapindex> indexes
+type sitemapXML struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// seedFromSitemaps reads the sitemaps of startURL's site and queues every
+// internal page they list as a crawl root
+func (c *Checker) seedFromSitemaps(ctx context.Context, collector *colly.Collector, startURL string) {
+	if !c.config.UseSitemaps {
+		return
+	}
+
+	u, err := url.Parse(startURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return
+	}
+	origin := u.Scheme + "://" + u.Host
+
+	c.sitemaps.mu.Lock()
+	seen := c.sitemaps.origins[origin]
+	c.sitemaps.origins[origin] = true
+	c.sitemaps.mu.Unlock()
+	if seen {
+		return
+	}
+
+	for _, page := range c.readSitemaps(ctx, c.sitemapURLs(ctx, u)) {
+		if ctx.Err() != nil {
+			return
+		}
+		if !c.shouldFollow(page) || !c.robotsAllowed(ctx, page) || !c.claimPage(page) {
+			continue
+		}
+		if err := collector.Visit(page); err == nil {
+			c.journal.queued(page, 1)
+		}
+	}
+}
+
+// sitemapURLs returns the sitemaps to read for u's site: the configured ones,
+// else those listed in robots.txt, else /sitemap.xml
+func (c *Checker) sitemapURLs(ctx context.Context, u *url.URL) []string {
+	origin := u.Scheme + "://" + u.Host
+
+	var sitemaps []string
+	for _, sitemap := range c.config.Sitemaps {
+		if s, err := url.Parse(sitemap); err == nil && s.Scheme+"://"+s.Host == origin {
+			sitemaps = append(sitemaps, sitemap)
+		}
+	}
+	if len(sitemaps) > 0 {
+		return sitemaps
+	}
+
+	if robots := c.robots.get(ctx, u); robots != nil && len(robots.Sitemaps) > 0 {
+		return robots.Sitemaps
+	}
+	return []string{origin + "/sitemap.xml"}
+}
+
+// readSitemaps fetches sitemaps, following sitemap indexes, and returns the
+// pages they list
+func (c *Checker) readSitemaps(ctx context.Context, queue []string) []string {
+	var pages []string
+	fetched := make(map[string]bool)
+
+	for len(queue) > 0 && len(fetched) < maxSitemapFiles {
+		sitemapURL := queue[0]
+		queue = queue[1:]
+		if fetched[sitemapURL] {
+			continue
+		}
+		fetched[sitemapURL] = true
+
+		doc, err := c.fetchSitemap(ctx, sitemapURL)
+		if err != nil {
+			continue
+		}
+
+		c.sitemaps.mu.Lock()
+		c.sitemaps.files = append(c.sitemaps.files, sitemapURL)
+		for _, entry := range doc.URLs {
+			if page := strings.TrimSpace(entry.Loc); page != "" {
+				c.sitemaps.pages[pageKeyString(c.normalize(page))] = true
+				pages = append(pages, page)
+			}
+		}
+		c.sitemaps.mu.Unlock()
+
+		for _, entry := range doc.Sitemaps {
+			if child := strings.TrimSpace(entry.Loc); child != "" {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	return pages
+}
+
+// fetchSitemap downloads and parses one sitemap or sitemap index, gzipped or not
+func (c *Checker) fetchSitemap(ctx context.Context, sitemapURL string) (*sitemapXML, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, sitemapURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("sitemap %s: status %d", sitemapURL, resp.StatusCode)
+	}
+
+	// Detect gzip by its magic number: .xml.gz files are often served
+	// without a Content-Encoding the transport would undo for us
+	body := bufio.NewReader(resp.Body)
+	var r io.Reader = body
+	if magic, err := body.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("sitemap %s: %w", sitemapURL, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var doc sitemapXML
+	if err := xml.NewDecoder(io.LimitReader(r, maxSitemapSize)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("sitemap %s: %w", sitemapURL, err)
+	}
+	// Sites without a sitemap often answer with an HTML page instead of a 404
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, fmt.Errorf("sitemap %s: unexpected <%s> document", sitemapURL, doc.XMLName.Local)
+	}
+	return &doc, nil
+}
+
+// markLinked records that a crawled page links to target, a normalized URL
+func (s *sitemapIndex) markLinked(target string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.linked[pageKeyString(target)] = true
+}
+
+// markReached records that page, a normalized URL, was crawled successfully
+func (s *sitemapIndex) markReached(page string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reached[pageKeyString(page)] = true
+}
+
+// report compares sitemap pages with linked pages. Seeds, normalized, count
+// as linked.
+// It returns nil when no sitemap was read.
+func (s *sitemapIndex) report(seeds []string) *types.SitemapReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.files) == 0 {
+		return nil
+	}
+
+	linked := make(map[string]bool, len(s.linked)+len(seeds))
+	for page := range s.linked {
+		linked[page] = true
+	}
+	for _, seed := range seeds {
+		linked[pageKeyString(seed)] = true
+	}
+
+	report := &types.SitemapReport{
+		Sitemaps: s.files,
+		Pages:    len(s.pages),
+	}
+	for page := range s.pages {
+		if !linked[page] {
+			report.Orphans = append(report.Orphans, page)
+		}
+	}
+	for page := range s.reached {
+		if linked[page] && !s.pages[page] {
+			report.Unlisted = append(report.Unlisted, page)
+		}
+	}
+	slices.Sort(report.Orphans)
+	slices.Sort(report.Unlisted)
+	return report
+}
+
+// pageKeyString returns rawURL without its fragment
+func pageKeyString(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return pageKey(u)
+}
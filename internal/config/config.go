@@ -68,13 +68,60 @@ func (m *Manager) setDefaults() {
 
 	m.v.SetDefault("mode", defaults.Mode)
 	m.v.SetDefault("output_format", defaults.OutputFormat)
+	m.v.SetDefault("group_by", defaults.GroupBy)
+	m.v.SetDefault("csv.columns", defaults.CSV.Columns)
+	m.v.SetDefault("csv.header", defaults.CSV.Header)
+	m.v.SetDefault("template", defaults.Template)
 	m.v.SetDefault("concurrency", defaults.Concurrency)
 	m.v.SetDefault("timeout", defaults.Timeout)
 	m.v.SetDefault("max_depth", defaults.MaxDepth)
 	m.v.SetDefault("follow_redirects", defaults.FollowRedirects)
+	m.v.SetDefault("max_redirects", defaults.MaxRedirects)
+	m.v.SetDefault("request_method", defaults.RequestMethod)
+	m.v.SetDefault("get_fallback_codes", defaults.GetFallbackCodes)
+	m.v.SetDefault("range_bytes", defaults.RangeBytes)
+	m.v.SetDefault("retry.max_attempts", defaults.Retry.MaxAttempts)
+	m.v.SetDefault("retry.initial_backoff", defaults.Retry.InitialBackoff)
+	m.v.SetDefault("retry.max_backoff", defaults.Retry.MaxBackoff)
+	m.v.SetDefault("retry.multiplier", defaults.Retry.Multiplier)
+	m.v.SetDefault("retry.jitter", defaults.Retry.Jitter)
+	m.v.SetDefault("retry.retry_on_status", defaults.Retry.RetryOnStatus)
+	m.v.SetDefault("retry.retry_on_errors", defaults.Retry.RetryOnErrors)
+	m.v.SetDefault("retry.respect_retry_after", defaults.Retry.RespectRetryAfter)
+	m.v.SetDefault("retry.max_retry_after", defaults.Retry.MaxRetryAfter)
+	m.v.SetDefault("rate_limit.requests_per_second", defaults.RateLimit.RequestsPerSecond)
+	m.v.SetDefault("rate_limit.max_in_flight", defaults.RateLimit.MaxInFlight)
+	m.v.SetDefault("rate_limit.adaptive", defaults.RateLimit.Adaptive)
+	m.v.SetDefault("follow.include", defaults.Follow.Include)
+	m.v.SetDefault("follow.exclude", defaults.Follow.Exclude)
+	m.v.SetDefault("follow.max_query_variants", defaults.Follow.MaxQueryVariants)
+	m.v.SetDefault("follow.subdomains", defaults.Follow.Subdomains)
+	m.v.SetDefault("follow.respect_nofollow", defaults.Follow.RespectNofollow)
+	m.v.SetDefault("follow.respect_canonical", defaults.Follow.RespectCanonical)
+	m.v.SetDefault("check.include", defaults.Check.Include)
+	m.v.SetDefault("check.exclude", defaults.Check.Exclude)
+	m.v.SetDefault("check.max_query_variants", defaults.Check.MaxQueryVariants)
+	m.v.SetDefault("budget.max_pages", defaults.Budget.MaxPages)
+	m.v.SetDefault("budget.max_links", defaults.Budget.MaxLinks)
+	m.v.SetDefault("budget.max_duration", defaults.Budget.MaxDuration)
+	m.v.SetDefault("budget.max_host_requests", defaults.Budget.MaxHostRequests)
+	m.v.SetDefault("budget.max_bytes", defaults.Budget.MaxBytes)
+	m.v.SetDefault("normalize.strip_params", defaults.Normalize.StripParams)
+	m.v.SetDefault("normalize.sort_params", defaults.Normalize.SortParams)
+	m.v.SetDefault("normalize.trailing_slash", defaults.Normalize.TrailingSlash)
+	m.v.SetDefault("normalize.strip_fragment", defaults.Normalize.StripFragment)
+	m.v.SetDefault("directory.base_url", defaults.Directory.BaseURL)
+	m.v.SetDefault("directory.index_files", defaults.Directory.IndexFiles)
+	m.v.SetDefault("directory.clean_urls", defaults.Directory.CleanURLs)
 	m.v.SetDefault("check_external_only", defaults.CheckExternalOnly)
+	m.v.SetDefault("check_scope", defaults.CheckScope)
 	m.v.SetDefault("user_agent", defaults.UserAgent)
 	m.v.SetDefault("respect_robots_txt", defaults.RespectRobotsTxt)
+	m.v.SetDefault("extractors", defaults.Extractors)
+	m.v.SetDefault("check_fragments", defaults.CheckFragments)
+	m.v.SetDefault("use_sitemaps", defaults.UseSitemaps)
+	m.v.SetDefault("sitemaps", defaults.Sitemaps)
+	m.v.SetDefault("status_rules", defaults.StatusRules)
 	m.v.SetDefault("verbose", defaults.Verbose)
 	m.v.SetDefault("show_progress", defaults.ShowProgress)
 }
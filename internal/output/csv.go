@@ -0,0 +1,205 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// DefaultColumns are the CSV and TSV columns written when none are selected
+var DefaultColumns = []string{"url", "status", "status_code", "found_on", "error"}
+
+// csvColumn renders one LinkResult field, named by its JSON key, as a cell
+type csvColumn struct {
+	name string
+	cell func(link types.LinkResult) string
+}
+
+// csvColumns are the selectable columns, in LinkResult field order
+var csvColumns = []csvColumn{
+	{"url", func(l types.LinkResult) string { return l.URL }},
+	{"status", func(l types.LinkResult) string { return string(l.Status) }},
+	{"status_code", func(l types.LinkResult) string { return intCell(l.StatusCode) }},
+	{"error", func(l types.LinkResult) string { return l.Error }},
+	{"redirect_url", func(l types.LinkResult) string { return l.RedirectURL }},
+	{"redirects", func(l types.LinkResult) string {
+		if len(l.Redirects) == 0 {
+			return ""
+		}
+		return formatChain(l)
+	}},
+	{"redirect_type", func(l types.LinkResult) string { return string(l.RedirectType) }},
+	{"found_on", func(l types.LinkResult) string { return l.FoundOn }},
+	{"response_time", func(l types.LinkResult) string { return strconv.FormatInt(l.ResponseTime.Milliseconds(), 10) }},
+	{"checked_at", func(l types.LinkResult) string { return timeCell(l.CheckedAt) }},
+	{"content_type", func(l types.LinkResult) string { return l.ContentType }},
+	{"content_length", func(l types.LinkResult) string { return int64Cell(l.ContentLength) }},
+	{"method", func(l types.LinkResult) string { return l.Method }},
+	{"attempts", func(l types.LinkResult) string { return intCell(l.Attempts) }},
+	{"scope", func(l types.LinkResult) string { return string(l.Scope) }},
+	{"element", func(l types.LinkResult) string { return l.Element }},
+	{"attribute", func(l types.LinkResult) string { return l.Attribute }},
+	{"rule", func(l types.LinkResult) string { return l.Rule }},
+	{"location", func(l types.LinkResult) string {
+		if l.Location == nil {
+			return ""
+		}
+		return l.Location.String()
+	}},
+	{"referrers", func(l types.LinkResult) string {
+		pages := make([]string, 0, len(l.Referrers))
+		for _, ref := range l.Referrers {
+			if ref.Location != nil {
+				pages = append(pages, ref.Location.String())
+			} else {
+				pages = append(pages, ref.Page)
+			}
+		}
+		return strings.Join(pages, " | ")
+	}},
+	{"not_followed", func(l types.LinkResult) string { return string(l.NotFollowed) }},
+}
+
+// tsvReplacer flattens the characters that would break the rows of a TSV file
+var tsvReplacer = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+// ValidateColumns reports the first column name that is not a LinkResult field
+func ValidateColumns(columns []string) error {
+	_, err := lookupColumns(columns)
+	return err
+}
+
+// lookupColumns resolves column names to their columns
+func lookupColumns(names []string) ([]csvColumn, error) {
+	columns := make([]csvColumn, 0, len(names))
+	for _, name := range names {
+		i := slices.IndexFunc(csvColumns, func(c csvColumn) bool { return c.name == name })
+		if i < 0 {
+			available := make([]string, len(csvColumns))
+			for j, c := range csvColumns {
+				available[j] = c.name
+			}
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(available, ", "))
+		}
+		columns = append(columns, csvColumns[i])
+	}
+	return columns, nil
+}
+
+// CSVFormatter formats output as one row per link, comma separated, or tab
+// separated when Comma is '\t'. When streaming, each row is written the
+// moment its link is checked, so a large crawl is never held in memory as a
+// whole. Links that were never streamed, such as results restored from a
+// journal when resuming, are written by Format.
+type CSVFormatter struct {
+	Options
+	Comma rune
+
+	mu      sync.Mutex
+	cw      *csv.Writer
+	columns []csvColumn
+	sent    map[string]bool
+	err     error
+}
+
+func (f *CSVFormatter) Stream(w io.Writer) func(link types.LinkResult) {
+	f.mu.Lock()
+	f.start(w)
+	f.sent = make(map[string]bool)
+	f.mu.Unlock()
+
+	return func(link types.LinkResult) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.writeRow(link)
+		f.cw.Flush()
+		if f.err == nil {
+			f.err = f.cw.Error()
+		}
+	}
+}
+
+func (f *CSVFormatter) Format(result *types.CheckResult, w io.Writer) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.cw == nil {
+		f.start(w)
+	}
+	for _, link := range result.Links {
+		if !f.sent[link.URL] {
+			f.writeRow(link)
+		}
+	}
+	if f.err != nil {
+		return f.err
+	}
+
+	f.cw.Flush()
+	return f.cw.Error()
+}
+
+// start resolves the columns and writes the header row. The caller must
+// hold f.mu.
+func (f *CSVFormatter) start(w io.Writer) {
+	names := f.Columns
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+	f.columns, f.err = lookupColumns(names)
+
+	f.cw = csv.NewWriter(w)
+	if f.Comma != 0 {
+		f.cw.Comma = f.Comma
+	}
+	if f.err == nil && !f.NoHeader {
+		f.err = f.cw.Write(names)
+	}
+}
+
+// writeRow writes the row of a link, keeping the first error for Format to
+// report. The caller must hold f.mu.
+func (f *CSVFormatter) writeRow(link types.LinkResult) {
+	if f.err != nil {
+		return
+	}
+	row := make([]string, len(f.columns))
+	for i, column := range f.columns {
+		row[i] = column.cell(link)
+		if f.cw.Comma == '\t' {
+			row[i] = tsvReplacer.Replace(row[i])
+		}
+	}
+	if f.err = f.cw.Write(row); f.err == nil && f.sent != nil {
+		f.sent[link.URL] = true
+	}
+}
+
+// intCell renders a count that is zero when unknown as an empty cell
+func intCell(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func int64Cell(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+func timeCell(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
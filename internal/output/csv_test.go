@@ -0,0 +1,142 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// newCSVResult returns a check with cells CSV has to quote and TSV has to
+// flatten: a multi-line error and a link found in several places
+func newCSVResult() *types.CheckResult {
+	return &types.CheckResult{
+		Links: []types.LinkResult{
+			{URL: "https://example.com/", Status: types.StatusOK, StatusCode: 200},
+			{
+				URL:        "https://example.com/missing",
+				Status:     types.StatusDead,
+				StatusCode: 404,
+				Error:      "Not Found",
+				Referrers: []types.Referrer{
+					{Page: "docs/index.md", Location: &types.SourceLocation{File: "docs/index.md", Line: 3, Column: 5}},
+					{Page: "docs/guide.md", Location: &types.SourceLocation{File: "docs/guide.md", Line: 12, Column: 1}},
+				},
+			},
+			{
+				URL:       "https://other.example.org/slow",
+				Status:    types.StatusTimeout,
+				Error:     "request timed out after 5s\nwhile reading headers",
+				Referrers: []types.Referrer{{Page: "https://example.com/", Element: "a", Attribute: "href"}},
+			},
+		},
+	}
+}
+
+func TestCSVFormatter(t *testing.T) {
+	t.Run("quoting", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := &CSVFormatter{Options: Options{Columns: []string{"url", "status", "error", "referrers"}}}
+		if err := f.Format(newCSVResult(), &buf); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("Output is not valid CSV: %v", err)
+		}
+		if len(records) != 4 {
+			t.Fatalf("Expected a header and 3 rows, got %d records", len(records))
+		}
+		if strings.Join(records[0], ",") != "url,status,error,referrers" {
+			t.Errorf("Unexpected header %v", records[0])
+		}
+		// A cell with a newline survives quoting intact
+		if got := records[3][2]; got != "request timed out after 5s\nwhile reading headers" {
+			t.Errorf("Expected the multi-line error kept, got %q", got)
+		}
+		if got := records[2][3]; got != "docs/index.md:3:5 | docs/guide.md:12:1" {
+			t.Errorf("Expected both referrer locations, got %q", got)
+		}
+	})
+
+	t.Run("tsv flattens tabs and newlines", func(t *testing.T) {
+		result := newCSVResult()
+		result.Links[1].Error = "Not\tFound"
+
+		var buf bytes.Buffer
+		f := &CSVFormatter{Options: Options{Columns: []string{"url", "error"}, NoHeader: true}, Comma: '\t'}
+		if err := f.Format(result, &buf); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("Expected 3 rows without a header, got %d: %q", len(lines), lines)
+		}
+		if lines[1] != "https://example.com/missing\tNot Found" {
+			t.Errorf("Expected the tab in the error flattened, got %q", lines[1])
+		}
+		if lines[2] != "https://other.example.org/slow\trequest timed out after 5s while reading headers" {
+			t.Errorf("Expected the newline in the error flattened, got %q", lines[2])
+		}
+	})
+
+	t.Run("default columns", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := (&CSVFormatter{}).Format(newCSVResult(), &buf); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+		header, _, _ := strings.Cut(buf.String(), "\n")
+		if header != strings.Join(DefaultColumns, ",") {
+			t.Errorf("Expected the default columns, got %q", header)
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		if err := ValidateColumns([]string{"url", "nope"}); err == nil || !strings.Contains(err.Error(), `"nope"`) {
+			t.Errorf("Expected an error naming the unknown column, got %v", err)
+		}
+		var buf bytes.Buffer
+		if err := (&CSVFormatter{Options: Options{Columns: []string{"nope"}}}).Format(newCSVResult(), &buf); err == nil {
+			t.Error("Expected Format to reject an unknown column")
+		}
+		if buf.Len() != 0 {
+			t.Errorf("Expected nothing written for an unknown column, got %q", buf.String())
+		}
+	})
+
+	t.Run("streamed", func(t *testing.T) {
+		result := newCSVResult()
+		f := &CSVFormatter{Options: Options{Columns: []string{"url"}}}
+		var buf bytes.Buffer
+		stream := f.Stream(&buf)
+
+		// The first link was restored from a journal and never streamed
+		for _, link := range result.Links[1:] {
+			stream(link)
+		}
+		if got := buf.String(); got != "url\nhttps://example.com/missing\nhttps://other.example.org/slow\n" {
+			t.Fatalf("Expected the header and a row per streamed link before Format, got %q", got)
+		}
+
+		if err := f.Format(result, &buf); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+		if got := buf.String(); got != "url\nhttps://example.com/missing\nhttps://other.example.org/slow\nhttps://example.com/\n" {
+			t.Errorf("Expected every link written once, got %q", got)
+		}
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		f := &CSVFormatter{}
+		stream := f.Stream(failingWriter{})
+		stream(newCSVResult().Links[0])
+		if err := f.Format(newCSVResult(), failingWriter{}); !errors.Is(err, errWriteFailed) {
+			t.Errorf("Expected the streaming write error from Format, got %v", err)
+		}
+	})
+}
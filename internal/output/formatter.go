@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,26 +16,48 @@ type Formatter interface {
 	Format(result *types.CheckResult, w io.Writer) error
 }
 
+// Options controls the layout of a report
+type Options struct {
+	GroupBy  types.GroupBy // list links by status (default) or by the page they appear on
+	Columns  []string      // csv and tsv columns; empty writes DefaultColumns
+	NoHeader bool          // leave out the csv and tsv header row
+}
+
 // GetFormatter returns the appropriate formatter based on the output format
-func GetFormatter(format types.OutputFormat) Formatter {
+func GetFormatter(format types.OutputFormat, opts Options) Formatter {
 	switch format {
 	case types.FormatMarkdown:
-		return &MarkdownFormatter{}
+		return &MarkdownFormatter{Options: opts}
 	case types.FormatHTML:
-		return &HTMLFormatter{}
+		return &HTMLFormatter{Options: opts}
 	case types.FormatJSON:
 		return &JSONFormatter{}
+	case types.FormatSARIF:
+		return &SARIFFormatter{}
+	case types.FormatJUnit:
+		return &JUnitFormatter{Options: opts}
+	case types.FormatCSV:
+		return &CSVFormatter{Options: opts}
+	case types.FormatTSV:
+		return &CSVFormatter{Options: opts, Comma: '\t'}
+	case types.FormatNDJSON:
+		return &NDJSONFormatter{}
 	default:
-		return &PlaintextFormatter{}
+		return &PlaintextFormatter{Options: opts}
 	}
 }
 
 // PlaintextFormatter formats output as plain text
-type PlaintextFormatter struct{}
+type PlaintextFormatter struct {
+	Options
+}
 
 func (f *PlaintextFormatter) Format(result *types.CheckResult, w io.Writer) error {
 	fmt.Fprintf(w, "Link Check Report\n")
 	fmt.Fprintf(w, "=================\n\n")
+	if result.Incomplete {
+		fmt.Fprintf(w, "WARNING: %s\n\n", incompleteNotice(result))
+	}
 	fmt.Fprintf(w, "Summary:\n")
 	fmt.Fprintf(w, "  Start Time:    %s\n", result.StartTime.Format(time.RFC3339))
 	fmt.Fprintf(w, "  End Time:      %s\n", result.EndTime.Format(time.RFC3339))
@@ -43,65 +66,177 @@ func (f *PlaintextFormatter) Format(result *types.CheckResult, w io.Writer) erro
 	fmt.Fprintf(w, "  OK:            %d\n", result.TotalOK)
 	fmt.Fprintf(w, "  Dead:          %d\n", result.TotalDead)
 	fmt.Fprintf(w, "  Redirects:     %d\n", result.TotalRedirect)
-	fmt.Fprintf(w, "  Errors:        %d\n\n", result.TotalErrors)
+	fmt.Fprintf(w, "  Moved:         %d\n", result.TotalMoved)
+	fmt.Fprintf(w, "  Errors:        %d\n", result.TotalErrors)
+	fmt.Fprintf(w, "  Blocked:       %d\n", result.TotalBlocked)
+	fmt.Fprintf(w, "  Rate Limited:  %d\n", result.TotalLimited)
+	fmt.Fprintf(w, "  Skipped:       %d\n\n", result.TotalSkipped)
+
+	fmt.Fprintf(w, "By Link Type:\n")
+	fmt.Fprintf(w, "  Internal:      %s\n", formatTotals(result.Internal))
+	fmt.Fprintf(w, "  External:      %s\n\n", formatTotals(result.External))
+
+	if f.GroupBy == types.GroupByPage {
+		f.formatByPage(result, w)
+	} else {
+		f.formatByStatus(result, w)
+	}
+
+	if skipped := notFollowedLinks(result.Links); len(skipped) > 0 {
+		fmt.Fprintf(w, "Not Followed (%d):\n", len(skipped))
+		fmt.Fprintf(w, "%s\n", strings.Repeat("-", 80))
+		for _, link := range skipped {
+			fmt.Fprintf(w, "  [%s] %s\n", link.NotFollowed, link.URL)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	if sm := result.Sitemap; sm != nil {
+		fmt.Fprintf(w, "Sitemap: %d pages in %d sitemaps\n", sm.Pages, len(sm.Sitemaps))
+		fmt.Fprintf(w, "%s\n", strings.Repeat("-", 80))
+		for _, section := range sitemapSections(sm) {
+			if len(section.pages) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "%s (%d):\n", section.title, len(section.pages))
+			for _, page := range section.pages {
+				fmt.Fprintf(w, "  %s\n", page)
+			}
+		}
+		fmt.Fprintf(w, "\n")
+	}
 
+	return nil
+}
+
+// formatByStatus lists links under their status
+func (f *PlaintextFormatter) formatByStatus(result *types.CheckResult, w io.Writer) {
 	// Group links by status
 	byStatus := groupByStatus(result.Links)
 
-	if len(byStatus[types.StatusDead]) > 0 {
-		fmt.Fprintf(w, "Dead Links (%d):\n", len(byStatus[types.StatusDead]))
+	if dead := deadLinks(byStatus); len(dead) > 0 {
+		fmt.Fprintf(w, "Dead Links (%d):\n", len(dead))
 		fmt.Fprintf(w, "%s\n", strings.Repeat("-", 80))
-		for _, link := range byStatus[types.StatusDead] {
+		for _, link := range dead {
 			fmt.Fprintf(w, "  [%d] %s\n", link.StatusCode, link.URL)
 			if link.FoundOn != "" {
-				fmt.Fprintf(w, "       Found on: %s\n", link.FoundOn)
+				fmt.Fprintf(w, "       Found on: %s%s%s\n", foundOn(link), sourceSuffix(link), alsoFoundOn(link))
 			}
 			if link.Error != "" {
 				fmt.Fprintf(w, "       Error: %s\n", link.Error)
 			}
+			if link.Rule != "" {
+				fmt.Fprintf(w, "       Rule: %s\n", link.Rule)
+			}
 		}
 		fmt.Fprintf(w, "\n")
 	}
 
-	if len(byStatus[types.StatusError]) > 0 || len(byStatus[types.StatusTimeout]) > 0 {
-		errors := append(byStatus[types.StatusError], byStatus[types.StatusTimeout]...)
+	if errors := errorLinks(byStatus); len(errors) > 0 {
 		fmt.Fprintf(w, "Errors (%d):\n", len(errors))
 		fmt.Fprintf(w, "%s\n", strings.Repeat("-", 80))
 		for _, link := range errors {
 			fmt.Fprintf(w, "  [%s] %s\n", link.Status, link.URL)
 			if link.FoundOn != "" {
-				fmt.Fprintf(w, "       Found on: %s\n", link.FoundOn)
+				fmt.Fprintf(w, "       Found on: %s%s%s\n", foundOn(link), sourceSuffix(link), alsoFoundOn(link))
 			}
 			if link.Error != "" {
 				fmt.Fprintf(w, "       Error: %s\n", link.Error)
 			}
+			if link.Rule != "" {
+				fmt.Fprintf(w, "       Rule: %s\n", link.Rule)
+			}
 		}
 		fmt.Fprintf(w, "\n")
 	}
 
-	if len(byStatus[types.StatusRedirect]) > 0 {
-		fmt.Fprintf(w, "Redirects (%d):\n", len(byStatus[types.StatusRedirect]))
+	if limited := byStatus[types.StatusRateLimited]; len(limited) > 0 {
+		fmt.Fprintf(w, "Rate Limited (%d):\n", len(limited))
 		fmt.Fprintf(w, "%s\n", strings.Repeat("-", 80))
-		for _, link := range byStatus[types.StatusRedirect] {
+		for _, link := range limited {
 			fmt.Fprintf(w, "  [%d] %s\n", link.StatusCode, link.URL)
+			if link.FoundOn != "" {
+				fmt.Fprintf(w, "       Found on: %s%s%s\n", foundOn(link), sourceSuffix(link), alsoFoundOn(link))
+			}
+			if link.Rule != "" {
+				fmt.Fprintf(w, "       Rule: %s\n", link.Rule)
+			}
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	permanent, temporary := redirectsByType(result.Links)
+	for _, section := range []struct {
+		title string
+		links []types.LinkResult
+	}{
+		{"Permanent Redirects - update these links", permanent},
+		{"Temporary Redirects", temporary},
+	} {
+		if len(section.links) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s (%d):\n", section.title, len(section.links))
+		fmt.Fprintf(w, "%s\n", strings.Repeat("-", 80))
+		for _, link := range section.links {
+			fmt.Fprintf(w, "  [%d] %s\n", redirectCode(link), link.URL)
 			if link.RedirectURL != "" {
 				fmt.Fprintf(w, "       -> %s\n", link.RedirectURL)
 			}
+			if len(link.Redirects) > 1 {
+				fmt.Fprintf(w, "       Chain: %s\n", formatChain(link))
+			}
 			if link.FoundOn != "" {
-				fmt.Fprintf(w, "       Found on: %s\n", link.FoundOn)
+				fmt.Fprintf(w, "       Found on: %s%s%s\n", foundOn(link), sourceSuffix(link), alsoFoundOn(link))
 			}
 		}
 		fmt.Fprintf(w, "\n")
 	}
+}
 
-	return nil
+// formatByPage lists the links that need fixing under each page they appear on
+func (f *PlaintextFormatter) formatByPage(result *types.CheckResult, w io.Writer) {
+	pages := issuesByPage(result.Links)
+	if len(pages) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "Issues by Page (%d pages):\n", len(pages))
+	fmt.Fprintf(w, "%s\n", strings.Repeat("=", 80))
+	for _, page := range pages {
+		fmt.Fprintf(w, "%s (%d):\n", pageTitle(page.page), len(page.issues))
+		fmt.Fprintf(w, "%s\n", strings.Repeat("-", 80))
+		for _, issue := range page.issues {
+			fmt.Fprintf(w, "  [%s] %s\n", issueStatus(issue.link), issue.link.URL)
+			if issue.ref.Text != "" {
+				fmt.Fprintf(w, "       Text: %q\n", issue.ref.Text)
+			}
+			if issue.ref.Location != nil {
+				fmt.Fprintf(w, "       At: %s\n", issue.ref.Location)
+			} else if issue.ref.Element != "" {
+				fmt.Fprintf(w, "       Element: %s[%s]\n", issue.ref.Element, issue.ref.Attribute)
+			}
+			if issue.link.Error != "" {
+				fmt.Fprintf(w, "       Error: %s\n", issue.link.Error)
+			}
+			if issue.link.RedirectType == types.RedirectPermanent && issue.link.RedirectURL != "" {
+				fmt.Fprintf(w, "       Moved to: %s\n", issue.link.RedirectURL)
+			}
+		}
+		fmt.Fprintf(w, "\n")
+	}
 }
 
 // MarkdownFormatter formats output as Markdown
-type MarkdownFormatter struct{}
+type MarkdownFormatter struct {
+	Options
+}
 
 func (f *MarkdownFormatter) Format(result *types.CheckResult, w io.Writer) error {
 	fmt.Fprintf(w, "# Link Check Report\n\n")
+	if result.Incomplete {
+		fmt.Fprintf(w, "> ⚠️ **Incomplete:** %s\n\n", incompleteNotice(result))
+	}
 
 	// Summary table
 	fmt.Fprintf(w, "## Summary\n\n")
@@ -114,59 +249,179 @@ func (f *MarkdownFormatter) Format(result *types.CheckResult, w io.Writer) error
 	fmt.Fprintf(w, "| ✅ OK | %d |\n", result.TotalOK)
 	fmt.Fprintf(w, "| ❌ Dead | %d |\n", result.TotalDead)
 	fmt.Fprintf(w, "| 🔀 Redirects | %d |\n", result.TotalRedirect)
-	fmt.Fprintf(w, "| ⚠️ Errors | %d |\n\n", result.TotalErrors)
+	fmt.Fprintf(w, "| ➡️ Moved permanently | %d |\n", result.TotalMoved)
+	fmt.Fprintf(w, "| ⚠️ Errors | %d |\n", result.TotalErrors)
+	fmt.Fprintf(w, "| 🤖 Blocked by robots.txt | %d |\n", result.TotalBlocked)
+	fmt.Fprintf(w, "| ⏳ Rate limited | %d |\n", result.TotalLimited)
+	fmt.Fprintf(w, "| ⏭️ Skipped | %d |\n\n", result.TotalSkipped)
+
+	// Internal/external breakdown
+	fmt.Fprintf(w, "## By Link Type\n\n")
+	fmt.Fprintf(w, "| Type | Checked | OK | Dead | Redirects | Errors | Blocked | Skipped |\n")
+	fmt.Fprintf(w, "|------|---------|----|------|-----------|--------|---------|---------|\n")
+	for _, row := range scopeRows(result) {
+		fmt.Fprintf(w, "| %s | %d | %d | %d | %d | %d | %d | %d |\n", row.label,
+			row.totals.Checked, row.totals.OK, row.totals.Dead, row.totals.Redirect, row.totals.Errors, row.totals.Blocked, row.totals.Skipped)
+	}
+	fmt.Fprintf(w, "\n")
+
+	if f.GroupBy == types.GroupByPage {
+		f.formatByPage(result, w)
+	} else {
+		f.formatByStatus(result, w)
+	}
 
+	if skipped := notFollowedLinks(result.Links); len(skipped) > 0 {
+		fmt.Fprintf(w, "## 🚫 Not Followed (%d)\n\n", len(skipped))
+		for _, link := range skipped {
+			fmt.Fprintf(w, "- **[%s]** `%s`\n", link.NotFollowed, link.URL)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	if sm := result.Sitemap; sm != nil {
+		fmt.Fprintf(w, "## 🗺️ Sitemap\n\n")
+		fmt.Fprintf(w, "%d pages listed in %d sitemaps.\n\n", sm.Pages, len(sm.Sitemaps))
+		for _, section := range sitemapSections(sm) {
+			if len(section.pages) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "### %s (%d)\n\n", section.title, len(section.pages))
+			for _, page := range section.pages {
+				fmt.Fprintf(w, "- <%s>\n", page)
+			}
+			fmt.Fprintf(w, "\n")
+		}
+	}
+
+	return nil
+}
+
+// formatByStatus lists links under their status
+func (f *MarkdownFormatter) formatByStatus(result *types.CheckResult, w io.Writer) {
 	// Group links by status
 	byStatus := groupByStatus(result.Links)
 
-	if len(byStatus[types.StatusDead]) > 0 {
-		fmt.Fprintf(w, "## ❌ Dead Links (%d)\n\n", len(byStatus[types.StatusDead]))
-		for _, link := range byStatus[types.StatusDead] {
+	if dead := deadLinks(byStatus); len(dead) > 0 {
+		fmt.Fprintf(w, "## ❌ Dead Links (%d)\n\n", len(dead))
+		for _, link := range dead {
 			fmt.Fprintf(w, "- **[%d]** `%s`\n", link.StatusCode, link.URL)
 			if link.FoundOn != "" {
-				fmt.Fprintf(w, "  - Found on: <%s>\n", link.FoundOn)
+				fmt.Fprintf(w, "  - Found on: %s%s%s\n", markdownFoundOn(link), sourceSuffix(link), alsoFoundOn(link))
 			}
 			if link.Error != "" {
 				fmt.Fprintf(w, "  - Error: `%s`\n", link.Error)
 			}
+			if link.Rule != "" {
+				fmt.Fprintf(w, "  - Rule: `%s`\n", link.Rule)
+			}
 		}
 		fmt.Fprintf(w, "\n")
 	}
 
-	if len(byStatus[types.StatusError]) > 0 || len(byStatus[types.StatusTimeout]) > 0 {
-		errors := append(byStatus[types.StatusError], byStatus[types.StatusTimeout]...)
+	if errors := errorLinks(byStatus); len(errors) > 0 {
 		fmt.Fprintf(w, "## ⚠️ Errors (%d)\n\n", len(errors))
 		for _, link := range errors {
 			fmt.Fprintf(w, "- **[%s]** `%s`\n", link.Status, link.URL)
 			if link.FoundOn != "" {
-				fmt.Fprintf(w, "  - Found on: <%s>\n", link.FoundOn)
+				fmt.Fprintf(w, "  - Found on: %s%s%s\n", markdownFoundOn(link), sourceSuffix(link), alsoFoundOn(link))
 			}
 			if link.Error != "" {
 				fmt.Fprintf(w, "  - Error: `%s`\n", link.Error)
 			}
+			if link.Rule != "" {
+				fmt.Fprintf(w, "  - Rule: `%s`\n", link.Rule)
+			}
 		}
 		fmt.Fprintf(w, "\n")
 	}
 
-	if len(byStatus[types.StatusRedirect]) > 0 {
-		fmt.Fprintf(w, "## 🔀 Redirects (%d)\n\n", len(byStatus[types.StatusRedirect]))
-		for _, link := range byStatus[types.StatusRedirect] {
+	if limited := byStatus[types.StatusRateLimited]; len(limited) > 0 {
+		fmt.Fprintf(w, "## ⏳ Rate Limited (%d)\n\n", len(limited))
+		for _, link := range limited {
 			fmt.Fprintf(w, "- **[%d]** `%s`\n", link.StatusCode, link.URL)
+			if link.FoundOn != "" {
+				fmt.Fprintf(w, "  - Found on: %s%s%s\n", markdownFoundOn(link), sourceSuffix(link), alsoFoundOn(link))
+			}
+			if link.Rule != "" {
+				fmt.Fprintf(w, "  - Rule: `%s`\n", link.Rule)
+			}
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	permanent, temporary := redirectsByType(result.Links)
+	for _, section := range []struct {
+		title string
+		note  string
+		links []types.LinkResult
+	}{
+		{"➡️ Permanent Redirects", "These links have moved for good; update them to point at the new location.", permanent},
+		{"🔀 Temporary Redirects", "", temporary},
+	} {
+		if len(section.links) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "## %s (%d)\n\n", section.title, len(section.links))
+		if section.note != "" {
+			fmt.Fprintf(w, "%s\n\n", section.note)
+		}
+		for _, link := range section.links {
+			fmt.Fprintf(w, "- **[%d]** `%s`\n", redirectCode(link), link.URL)
 			if link.RedirectURL != "" {
 				fmt.Fprintf(w, "  - Redirects to: <%s>\n", link.RedirectURL)
 			}
+			if len(link.Redirects) > 1 {
+				fmt.Fprintf(w, "  - Chain: %s\n", formatChain(link))
+			}
 			if link.FoundOn != "" {
-				fmt.Fprintf(w, "  - Found on: <%s>\n", link.FoundOn)
+				fmt.Fprintf(w, "  - Found on: %s%s%s\n", markdownFoundOn(link), sourceSuffix(link), alsoFoundOn(link))
 			}
 		}
 		fmt.Fprintf(w, "\n")
 	}
+}
 
-	return nil
+// formatByPage lists the links that need fixing under each page they appear on
+func (f *MarkdownFormatter) formatByPage(result *types.CheckResult, w io.Writer) {
+	pages := issuesByPage(result.Links)
+	if len(pages) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "## 📄 Issues by Page (%d)\n\n", len(pages))
+	for _, page := range pages {
+		title := pageTitle(page.page)
+		if page.page != "" {
+			title = "`" + title + "`"
+		}
+		fmt.Fprintf(w, "### %s (%d)\n\n", title, len(page.issues))
+		for _, issue := range page.issues {
+			fmt.Fprintf(w, "- **[%s]** `%s`", issueStatus(issue.link), issue.link.URL)
+			if issue.ref.Text != "" {
+				fmt.Fprintf(w, " — %q", issue.ref.Text)
+			}
+			fmt.Fprintf(w, "\n")
+			if issue.ref.Location != nil {
+				fmt.Fprintf(w, "  - At: `%s`\n", issue.ref.Location)
+			} else if issue.ref.Element != "" {
+				fmt.Fprintf(w, "  - Element: `%s[%s]`\n", issue.ref.Element, issue.ref.Attribute)
+			}
+			if issue.link.Error != "" {
+				fmt.Fprintf(w, "  - Error: `%s`\n", issue.link.Error)
+			}
+			if issue.link.RedirectType == types.RedirectPermanent && issue.link.RedirectURL != "" {
+				fmt.Fprintf(w, "  - Moved to: <%s>\n", issue.link.RedirectURL)
+			}
+		}
+		fmt.Fprintf(w, "\n")
+	}
 }
 
 // HTMLFormatter formats output as HTML
-type HTMLFormatter struct{}
+type HTMLFormatter struct {
+	Options
+}
 
 func (f *HTMLFormatter) Format(result *types.CheckResult, w io.Writer) error {
 	fmt.Fprintf(w, `<!DOCTYPE html>
@@ -199,6 +454,12 @@ func (f *HTMLFormatter) Format(result *types.CheckResult, w io.Writer) error {
             color: #555;
             margin-top: 30px;
         }
+        .incomplete {
+            background: #fff7ed;
+            border-left: 4px solid #f59e0b;
+            padding: 10px 15px;
+            margin: 20px 0;
+        }
         .summary {
             display: grid;
             grid-template-columns: repeat(auto-fit, minmax(200px, 1fr));
@@ -255,11 +516,23 @@ func (f *HTMLFormatter) Format(result *types.CheckResult, w io.Writer) error {
         .badge.dead { background: #f44336; color: white; }
         .badge.error { background: #ff9800; color: white; }
         .badge.redirect { background: #2196F3; color: white; }
+        .badge.skipped { background: #9e9e9e; color: white; }
+        .scope-table {
+            border-collapse: collapse;
+            margin: 10px 0;
+        }
+        .scope-table th, .scope-table td {
+            padding: 6px 14px;
+            border-bottom: 1px solid #eee;
+            text-align: right;
+        }
+        .scope-table th:first-child, .scope-table td:first-child { text-align: left; }
     </style>
 </head>
 <body>
     <div class="container">
         <h1>🔗 Link Check Report</h1>
+%s
 
         <div class="summary">
             <div class="stat-card">
@@ -278,81 +551,231 @@ func (f *HTMLFormatter) Format(result *types.CheckResult, w io.Writer) error {
                 <div class="stat-label">🔀 Redirects</div>
                 <div class="stat-value">%d</div>
             </div>
+            <div class="stat-card redirect">
+                <div class="stat-label">➡️ Moved</div>
+                <div class="stat-value">%d</div>
+            </div>
             <div class="stat-card error">
                 <div class="stat-label">⚠️ Errors</div>
                 <div class="stat-value">%d</div>
             </div>
+            <div class="stat-card">
+                <div class="stat-label">🤖 Blocked</div>
+                <div class="stat-value">%d</div>
+            </div>
+            <div class="stat-card">
+                <div class="stat-label">⏳ Rate Limited</div>
+                <div class="stat-value">%d</div>
+            </div>
+            <div class="stat-card">
+                <div class="stat-label">⏭️ Skipped</div>
+                <div class="stat-value">%d</div>
+            </div>
             <div class="stat-card">
                 <div class="stat-label">Duration</div>
                 <div class="stat-value" style="font-size: 18px;">%s</div>
             </div>
         </div>
-`, result.TotalChecked, result.TotalOK, result.TotalDead, result.TotalRedirect,
-   result.TotalErrors, result.Duration.Round(time.Millisecond))
+`, incompleteBanner(result), result.TotalChecked, result.TotalOK, result.TotalDead, result.TotalRedirect, result.TotalMoved,
+		result.TotalErrors, result.TotalBlocked, result.TotalLimited, result.TotalSkipped, result.Duration.Round(time.Millisecond))
+
+	fmt.Fprintf(w, `        <h2>By Link Type</h2>
+        <table class="scope-table">
+            <tr><th>Type</th><th>Checked</th><th>OK</th><th>Dead</th><th>Redirects</th><th>Errors</th><th>Blocked</th><th>Skipped</th></tr>
+`)
+	for _, row := range scopeRows(result) {
+		fmt.Fprintf(w, "            <tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td></tr>\n", row.label,
+			row.totals.Checked, row.totals.OK, row.totals.Dead, row.totals.Redirect, row.totals.Errors, row.totals.Blocked, row.totals.Skipped)
+	}
+	fmt.Fprintf(w, "        </table>\n")
+
+	if f.GroupBy == types.GroupByPage {
+		f.formatByPage(result, w)
+	} else {
+		f.formatByStatus(result, w)
+	}
+
+	if skipped := notFollowedLinks(result.Links); len(skipped) > 0 {
+		fmt.Fprintf(w, "<h2>🚫 Not Followed (%d)</h2>\n", len(skipped))
+		for _, link := range skipped {
+			fmt.Fprintf(w, `<div class="link-item">
+    <div><span class="badge skipped">%s</span><span class="link-url">%s</span></div>
+</div>
+`, escapeHTML(string(link.NotFollowed)), escapeHTML(link.URL))
+		}
+	}
+
+	if sm := result.Sitemap; sm != nil {
+		fmt.Fprintf(w, "<h2>🗺️ Sitemap</h2>\n")
+		fmt.Fprintf(w, `<div class="link-meta">%d pages listed in %d sitemaps</div>
+`, sm.Pages, len(sm.Sitemaps))
+		for _, section := range sitemapSections(sm) {
+			if len(section.pages) == 0 {
+				continue
+			}
+			fmt.Fprintf(w, "<h3>%s (%d)</h3>\n", section.title, len(section.pages))
+			for _, page := range section.pages {
+				fmt.Fprintf(w, `<div class="link-item"><a class="link-url" href="%s">%s</a></div>
+`, escapeHTML(page), escapeHTML(page))
+			}
+		}
+	}
 
+	fmt.Fprintf(w, `
+    </div>
+</body>
+</html>
+`)
+	return nil
+}
+
+// formatByStatus lists links under their status
+func (f *HTMLFormatter) formatByStatus(result *types.CheckResult, w io.Writer) {
 	byStatus := groupByStatus(result.Links)
 
-	if len(byStatus[types.StatusDead]) > 0 {
-		fmt.Fprintf(w, "<h2>❌ Dead Links (%d)</h2>\n", len(byStatus[types.StatusDead]))
-		for _, link := range byStatus[types.StatusDead] {
+	if dead := deadLinks(byStatus); len(dead) > 0 {
+		fmt.Fprintf(w, "<h2>❌ Dead Links (%d)</h2>\n", len(dead))
+		for _, link := range dead {
 			fmt.Fprintf(w, `<div class="link-item dead">
     <div><span class="badge dead">%d</span><span class="link-url">%s</span></div>
 `, link.StatusCode, escapeHTML(link.URL))
 			if link.FoundOn != "" {
-				fmt.Fprintf(w, `    <div class="link-meta">Found on: <a href="%s">%s</a></div>
-`, link.FoundOn, escapeHTML(link.FoundOn))
+				fmt.Fprintf(w, `    <div class="link-meta">Found on: <a href="%s">%s</a>%s%s</div>
+`, link.FoundOn, escapeHTML(foundOn(link)), escapeHTML(sourceSuffix(link)), alsoFoundOn(link))
 			}
 			if link.Error != "" {
 				fmt.Fprintf(w, `    <div class="link-meta">Error: %s</div>
 `, escapeHTML(link.Error))
+			}
+			if link.Rule != "" {
+				fmt.Fprintf(w, `    <div class="link-meta">Rule: %s</div>
+`, escapeHTML(link.Rule))
 			}
 			fmt.Fprintf(w, "</div>\n")
 		}
 	}
 
-	if len(byStatus[types.StatusError]) > 0 || len(byStatus[types.StatusTimeout]) > 0 {
-		errors := append(byStatus[types.StatusError], byStatus[types.StatusTimeout]...)
+	if errors := errorLinks(byStatus); len(errors) > 0 {
 		fmt.Fprintf(w, "<h2>⚠️ Errors (%d)</h2>\n", len(errors))
 		for _, link := range errors {
 			fmt.Fprintf(w, `<div class="link-item error">
     <div><span class="badge error">%s</span><span class="link-url">%s</span></div>
 `, link.Status, escapeHTML(link.URL))
 			if link.FoundOn != "" {
-				fmt.Fprintf(w, `    <div class="link-meta">Found on: <a href="%s">%s</a></div>
-`, link.FoundOn, escapeHTML(link.FoundOn))
+				fmt.Fprintf(w, `    <div class="link-meta">Found on: <a href="%s">%s</a>%s%s</div>
+`, link.FoundOn, escapeHTML(foundOn(link)), escapeHTML(sourceSuffix(link)), alsoFoundOn(link))
 			}
 			if link.Error != "" {
 				fmt.Fprintf(w, `    <div class="link-meta">Error: %s</div>
 `, escapeHTML(link.Error))
+			}
+			if link.Rule != "" {
+				fmt.Fprintf(w, `    <div class="link-meta">Rule: %s</div>
+`, escapeHTML(link.Rule))
+			}
+			fmt.Fprintf(w, "</div>\n")
+		}
+	}
+
+	if limited := byStatus[types.StatusRateLimited]; len(limited) > 0 {
+		fmt.Fprintf(w, "<h2>⏳ Rate Limited (%d)</h2>\n", len(limited))
+		for _, link := range limited {
+			fmt.Fprintf(w, `<div class="link-item error">
+    <div><span class="badge error">%d</span><span class="link-url">%s</span></div>
+`, link.StatusCode, escapeHTML(link.URL))
+			if link.FoundOn != "" {
+				fmt.Fprintf(w, `    <div class="link-meta">Found on: <a href="%s">%s</a>%s%s</div>
+`, link.FoundOn, escapeHTML(foundOn(link)), escapeHTML(sourceSuffix(link)), alsoFoundOn(link))
+			}
+			if link.Rule != "" {
+				fmt.Fprintf(w, `    <div class="link-meta">Rule: %s</div>
+`, escapeHTML(link.Rule))
 			}
 			fmt.Fprintf(w, "</div>\n")
 		}
 	}
 
-	if len(byStatus[types.StatusRedirect]) > 0 {
-		fmt.Fprintf(w, "<h2>🔀 Redirects (%d)</h2>\n", len(byStatus[types.StatusRedirect]))
-		for _, link := range byStatus[types.StatusRedirect] {
+	permanent, temporary := redirectsByType(result.Links)
+	for _, section := range []struct {
+		title string
+		links []types.LinkResult
+	}{
+		{"➡️ Permanent Redirects", permanent},
+		{"🔀 Temporary Redirects", temporary},
+	} {
+		if len(section.links) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "<h2>%s (%d)</h2>\n", section.title, len(section.links))
+		for _, link := range section.links {
 			fmt.Fprintf(w, `<div class="link-item redirect">
     <div><span class="badge redirect">%d</span><span class="link-url">%s</span></div>
-`, link.StatusCode, escapeHTML(link.URL))
+`, redirectCode(link), escapeHTML(link.URL))
 			if link.RedirectURL != "" {
 				fmt.Fprintf(w, `    <div class="link-meta">Redirects to: <a href="%s">%s</a></div>
 `, link.RedirectURL, escapeHTML(link.RedirectURL))
+			}
+			if len(link.Redirects) > 1 {
+				fmt.Fprintf(w, `    <div class="link-meta">Chain: %s</div>
+`, escapeHTML(formatChain(link)))
 			}
 			if link.FoundOn != "" {
-				fmt.Fprintf(w, `    <div class="link-meta">Found on: <a href="%s">%s</a></div>
-`, link.FoundOn, escapeHTML(link.FoundOn))
+				fmt.Fprintf(w, `    <div class="link-meta">Found on: <a href="%s">%s</a>%s%s</div>
+`, link.FoundOn, escapeHTML(foundOn(link)), escapeHTML(sourceSuffix(link)), alsoFoundOn(link))
 			}
 			fmt.Fprintf(w, "</div>\n")
 		}
 	}
+}
 
-	fmt.Fprintf(w, `
-    </div>
-</body>
-</html>
-`)
-	return nil
+// formatByPage lists the links that need fixing under each page they appear on
+func (f *HTMLFormatter) formatByPage(result *types.CheckResult, w io.Writer) {
+	pages := issuesByPage(result.Links)
+	if len(pages) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "<h2>📄 Issues by Page (%d)</h2>\n", len(pages))
+	for _, page := range pages {
+		if page.page == "" {
+			fmt.Fprintf(w, "<h3>%s (%d)</h3>\n", pageTitle(page.page), len(page.issues))
+		} else {
+			fmt.Fprintf(w, `<h3><a href="%s">%s</a> (%d)</h3>
+`, escapeHTML(page.page), escapeHTML(page.page), len(page.issues))
+		}
+		for _, issue := range page.issues {
+			class := "dead"
+			if issue.link.Status != types.StatusDead && issue.link.Status != types.StatusMissingFragment {
+				class = "error"
+				if issue.link.RedirectType == types.RedirectPermanent {
+					class = "redirect"
+				}
+			}
+			fmt.Fprintf(w, `<div class="link-item %s">
+    <div><span class="badge %s">%s</span><span class="link-url">%s</span></div>
+`, class, class, escapeHTML(issueStatus(issue.link)), escapeHTML(issue.link.URL))
+			if issue.ref.Text != "" {
+				fmt.Fprintf(w, `    <div class="link-meta">Text: %s</div>
+`, escapeHTML(issue.ref.Text))
+			}
+			if issue.ref.Location != nil {
+				fmt.Fprintf(w, `    <div class="link-meta">At: %s</div>
+`, escapeHTML(issue.ref.Location.String()))
+			} else if issue.ref.Element != "" {
+				fmt.Fprintf(w, `    <div class="link-meta">Element: %s[%s]</div>
+`, escapeHTML(issue.ref.Element), escapeHTML(issue.ref.Attribute))
+			}
+			if issue.link.Error != "" {
+				fmt.Fprintf(w, `    <div class="link-meta">Error: %s</div>
+`, escapeHTML(issue.link.Error))
+			}
+			if issue.link.RedirectType == types.RedirectPermanent && issue.link.RedirectURL != "" {
+				fmt.Fprintf(w, `    <div class="link-meta">Moved to: <a href="%s">%s</a></div>
+`, escapeHTML(issue.link.RedirectURL), escapeHTML(issue.link.RedirectURL))
+			}
+			fmt.Fprintf(w, "</div>\n")
+		}
+	}
 }
 
 // JSONFormatter formats output as JSON
@@ -366,6 +789,57 @@ func (f *JSONFormatter) Format(result *types.CheckResult, w io.Writer) error {
 
 // Helper functions
 
+// scopeRow is one line of the internal/external breakdown
+type scopeRow struct {
+	label  string
+	totals types.LinkTotals
+}
+
+func scopeRows(result *types.CheckResult) []scopeRow {
+	return []scopeRow{
+		{"Internal", result.Internal},
+		{"External", result.External},
+	}
+}
+
+// foundOn returns where a link was found: its file:line:column in a source
+// file, or else the page it was found on
+func foundOn(link types.LinkResult) string {
+	if link.Location != nil {
+		return link.Location.String()
+	}
+	return link.FoundOn
+}
+
+// markdownFoundOn is foundOn formatted for Markdown
+func markdownFoundOn(link types.LinkResult) string {
+	if link.Location != nil {
+		return "`" + link.Location.String() + "`"
+	}
+	return "<" + link.FoundOn + ">"
+}
+
+// alsoFoundOn notes how many more places a link was found, e.g. " and 3 more"
+func alsoFoundOn(link types.LinkResult) string {
+	if len(link.Referrers) < 2 {
+		return ""
+	}
+	return fmt.Sprintf(" and %d more", len(link.Referrers)-1)
+}
+
+// sourceSuffix describes the element and attribute a link came from, e.g. " (img[src])"
+func sourceSuffix(link types.LinkResult) string {
+	if link.Element == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s[%s])", link.Element, link.Attribute)
+}
+
+func formatTotals(t types.LinkTotals) string {
+	return fmt.Sprintf("%d checked, %d ok, %d dead, %d redirects, %d errors, %d blocked, %d skipped",
+		t.Checked, t.OK, t.Dead, t.Redirect, t.Errors, t.Blocked, t.Skipped)
+}
+
 func groupByStatus(links []types.LinkResult) map[types.LinkStatus][]types.LinkResult {
 	grouped := make(map[types.LinkStatus][]types.LinkResult)
 	for _, link := range links {
@@ -374,6 +848,180 @@ func groupByStatus(links []types.LinkResult) map[types.LinkStatus][]types.LinkRe
 	return grouped
 }
 
+// incompleteNotice explains that a result only covers part of the links
+func incompleteNotice(result *types.CheckResult) string {
+	notice := "the check stopped early, so only part of the links were checked"
+	if result.StopReason != "" {
+		notice += fmt.Sprintf(" (%s)", result.StopReason)
+	}
+	return notice
+}
+
+// incompleteBanner returns the HTML notice for an incomplete result, or nothing
+func incompleteBanner(result *types.CheckResult) string {
+	if !result.Incomplete {
+		return ""
+	}
+	return fmt.Sprintf(`        <div class="incomplete">⚠️ <strong>Incomplete:</strong> %s</div>`,
+		escapeHTML(incompleteNotice(result)))
+}
+
+// errorLinks returns links that could not be checked, including broken redirect chains
+func errorLinks(byStatus map[types.LinkStatus][]types.LinkResult) []types.LinkResult {
+	var errors []types.LinkResult
+	for _, status := range []types.LinkStatus{
+		types.StatusError, types.StatusTimeout, types.StatusRedirectLoop, types.StatusTooManyRedirect,
+	} {
+		errors = append(errors, byStatus[status]...)
+	}
+	return errors
+}
+
+// redirectsByType splits links that were redirected into permanent and temporary redirects
+func redirectsByType(links []types.LinkResult) (permanent, temporary []types.LinkResult) {
+	for _, link := range links {
+		switch {
+		case link.RedirectType == types.RedirectPermanent:
+			permanent = append(permanent, link)
+		case link.RedirectType == types.RedirectTemporary || link.Status == types.StatusRedirect:
+			temporary = append(temporary, link)
+		}
+	}
+	return permanent, temporary
+}
+
+// redirectCode returns the status code of a link's first redirect
+func redirectCode(link types.LinkResult) int {
+	if len(link.Redirects) > 0 {
+		return link.Redirects[0].StatusCode
+	}
+	return link.StatusCode
+}
+
+// formatChain renders a redirect chain as "301 a → 302 b → c"
+func formatChain(link types.LinkResult) string {
+	parts := make([]string, 0, len(link.Redirects)+1)
+	for _, hop := range link.Redirects {
+		parts = append(parts, fmt.Sprintf("%d %s", hop.StatusCode, hop.URL))
+	}
+	if link.RedirectURL != "" {
+		parts = append(parts, link.RedirectURL)
+	}
+	return strings.Join(parts, " → ")
+}
+
+// deadLinks returns dead links followed by links whose #fragment is missing
+func deadLinks(byStatus map[types.LinkStatus][]types.LinkResult) []types.LinkResult {
+	dead := make([]types.LinkResult, 0, len(byStatus[types.StatusDead])+len(byStatus[types.StatusMissingFragment]))
+	dead = append(dead, byStatus[types.StatusDead]...)
+	return append(dead, byStatus[types.StatusMissingFragment]...)
+}
+
+// pageIssue is a link that needs fixing, as found at one place on a page
+type pageIssue struct {
+	link types.LinkResult
+	ref  types.Referrer
+}
+
+// pageIssues holds the links to fix on one page
+type pageIssues struct {
+	page   string
+	issues []pageIssue
+}
+
+// issuesByPage groups the links that need fixing by every page they appear
+// on, pages in order. Links found on no page, such as start URLs, are
+// grouped under an empty page, listed first.
+func issuesByPage(links []types.LinkResult) []pageIssues {
+	byPage := make(map[string][]pageIssue)
+	for _, link := range links {
+		if !needsFix(link) {
+			continue
+		}
+
+		refs := link.Referrers
+		if len(refs) == 0 {
+			refs = []types.Referrer{{Page: link.FoundOn, Element: link.Element, Attribute: link.Attribute, Location: link.Location}}
+		}
+		for _, ref := range refs {
+			byPage[ref.Page] = append(byPage[ref.Page], pageIssue{link: link, ref: ref})
+		}
+	}
+
+	pages := make([]pageIssues, 0, len(byPage))
+	for page, issues := range byPage {
+		// Source file issues read top to bottom
+		sort.SliceStable(issues, func(i, j int) bool {
+			a, b := issues[i].ref.Location, issues[j].ref.Location
+			if a == nil || b == nil {
+				return false
+			}
+			return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+		})
+		pages = append(pages, pageIssues{page: page, issues: issues})
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].page < pages[j].page })
+	return pages
+}
+
+// needsFix reports whether a link should be changed by the page's owner:
+// it is broken, or it has moved permanently
+func needsFix(link types.LinkResult) bool {
+	switch link.Status {
+	case types.StatusDead, types.StatusMissingFragment, types.StatusError, types.StatusTimeout,
+		types.StatusRedirectLoop, types.StatusTooManyRedirect:
+		return true
+	}
+	return link.RedirectType == types.RedirectPermanent
+}
+
+// issueStatus labels a link that needs fixing, e.g. "dead 404" or "moved 301"
+func issueStatus(link types.LinkResult) string {
+	switch {
+	case !needsFix(link):
+		return string(link.Status)
+	case link.RedirectType == types.RedirectPermanent && link.Status != types.StatusDead:
+		return fmt.Sprintf("moved %d", redirectCode(link))
+	case link.StatusCode > 0:
+		return fmt.Sprintf("%s %d", link.Status, link.StatusCode)
+	default:
+		return string(link.Status)
+	}
+}
+
+// pageTitle names a page in the by-page view
+func pageTitle(page string) string {
+	if page == "" {
+		return "Start URLs"
+	}
+	return page
+}
+
+// notFollowedLinks returns the links a crawl policy kept from being followed
+func notFollowedLinks(links []types.LinkResult) []types.LinkResult {
+	var skipped []types.LinkResult
+	for _, link := range links {
+		if link.NotFollowed != "" {
+			skipped = append(skipped, link)
+		}
+	}
+	return skipped
+}
+
+// sitemapSection is a titled list of pages from a sitemap report
+type sitemapSection struct {
+	title string
+	pages []string
+}
+
+// sitemapSections returns the orphan and unlisted page lists of a sitemap report
+func sitemapSections(sm *types.SitemapReport) []sitemapSection {
+	return []sitemapSection{
+		{"Orphan Pages (in sitemap, not linked)", sm.Orphans},
+		{"Missing From Sitemap", sm.Unlisted},
+	}
+}
+
 func escapeHTML(s string) string {
 	s = strings.ReplaceAll(s, "&", "&amp;")
 	s = strings.ReplaceAll(s, "<", "&lt;")
@@ -0,0 +1,100 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+func TestIssuesByPage(t *testing.T) {
+	result := &types.CheckResult{
+		Links: []types.LinkResult{
+			{URL: "https://example.com/", Status: types.StatusOK, StatusCode: 200},
+			{
+				URL:        "https://example.com/missing",
+				Status:     types.StatusDead,
+				StatusCode: 404,
+				Error:      "Not Found",
+				Referrers: []types.Referrer{
+					{Page: "docs/index.md", Location: &types.SourceLocation{File: "docs/index.md", Line: 3, Column: 5}},
+					{Page: "docs/guide.md", Location: &types.SourceLocation{File: "docs/guide.md", Line: 12, Column: 1}},
+				},
+			},
+			{
+				URL:       "https://other.example.org/slow",
+				Status:    types.StatusTimeout,
+				Error:     "request timed out after 5s",
+				Referrers: []types.Referrer{{Page: "https://example.com/", Element: "a", Attribute: "href"}},
+			},
+			{
+				URL:       "https://other.example.org/private",
+				Status:    types.StatusSkipped,
+				Referrers: []types.Referrer{{Page: "https://example.com/about", Element: "a", Attribute: "href"}},
+			},
+			{
+				URL:          "https://example.com/old",
+				Status:       types.StatusOK,
+				RedirectType: types.RedirectPermanent,
+				RedirectURL:  "https://example.com/new",
+				Redirects:    []types.RedirectHop{{URL: "https://example.com/old", StatusCode: 301}},
+				Referrers: []types.Referrer{
+					{Page: "docs/index.md", Location: &types.SourceLocation{File: "docs/index.md", Line: 1, Column: 1}},
+				},
+			},
+		},
+	}
+
+	pages := issuesByPage(result.Links)
+	var got []string
+	for _, page := range pages {
+		for _, issue := range page.issues {
+			got = append(got, page.page+" "+issue.link.URL)
+		}
+	}
+	// Pages in order, source file issues by line; ok and skipped links left out
+	expected := []string{
+		"docs/guide.md https://example.com/missing",
+		"docs/index.md https://example.com/old",
+		"docs/index.md https://example.com/missing",
+		"https://example.com/ https://other.example.org/slow",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected issues %q, got %q", expected, got)
+	}
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		want      []string
+	}{
+		{"plaintext", &PlaintextFormatter{Options{GroupBy: types.GroupByPage}}, []string{
+			"Issues by Page (3 pages):",
+			"docs/index.md (2):",
+			"  [moved 301] https://example.com/old",
+			"       At: docs/index.md:3:5",
+		}},
+		{"markdown", &MarkdownFormatter{Options{GroupBy: types.GroupByPage}}, []string{
+			"## 📄 Issues by Page (3)",
+			"### `docs/index.md` (2)",
+		}},
+		{"html", &HTMLFormatter{Options{GroupBy: types.GroupByPage}}, []string{
+			"<h2>📄 Issues by Page (3)</h2>",
+			`<h3><a href="docs/index.md">docs/index.md</a> (2)</h3>`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.formatter.Format(result, &buf); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// junitTimestamp is the ISO 8601 form without a zone that the JUnit schema expects
+const junitTimestamp = "2006-01-02T15:04:05"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+
+	elapsed time.Duration
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// JUnitFormatter formats output as JUnit XML for CI systems. Every checked
+// link is a test case, in a test suite per link host, or per page the link
+// was found on when grouping by page. Broken links fail and links that were
+// not checked are skipped.
+type JUnitFormatter struct {
+	Options
+}
+
+func (f *JUnitFormatter) Format(result *types.CheckResult, w io.Writer) error {
+	suites := make(map[string]*junitTestSuite)
+	suite := func(name string) *junitTestSuite {
+		s, ok := suites[name]
+		if !ok {
+			s = &junitTestSuite{Name: name, Timestamp: result.StartTime.UTC().Format(junitTimestamp)}
+			suites[name] = s
+		}
+		return s
+	}
+
+	for _, link := range result.Links {
+		if f.GroupBy == types.GroupByPage {
+			refs := link.Referrers
+			if len(refs) == 0 {
+				refs = []types.Referrer{{Page: link.FoundOn, Location: link.Location}}
+			}
+			for _, ref := range refs {
+				suite(pageTitle(ref.Page)).add(junitCase(link, ref, pageTitle(ref.Page)), link.ResponseTime)
+			}
+			continue
+		}
+
+		host := linkHost(link.URL)
+		ref := types.Referrer{Page: link.FoundOn, Location: link.Location}
+		suite(host).add(junitCase(link, ref, host), link.ResponseTime)
+	}
+
+	// A check cut short fails as a whole, not only by its exit code
+	if result.Incomplete {
+		suite("unlinked").add(junitTestCase{
+			Name:      "check completed",
+			ClassName: "unlinked",
+			Time:      junitSeconds(result.Duration),
+			Failure: &junitFailure{
+				Message: incompleteNotice(result),
+				Type:    "incomplete",
+			},
+		}, result.Duration)
+	}
+
+	root := junitTestSuites{Name: "unlinked", Time: junitSeconds(result.Duration)}
+	for _, s := range suites {
+		s.Time = junitSeconds(s.elapsed)
+		root.Tests += s.Tests
+		root.Failures += s.Failures
+		root.Skipped += s.Skipped
+		root.Suites = append(root.Suites, *s)
+	}
+	sort.Slice(root.Suites, func(i, j int) bool { return root.Suites[i].Name < root.Suites[j].Name })
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// add appends a test case that took elapsed to the suite and counts it
+func (s *junitTestSuite) add(tc junitTestCase, elapsed time.Duration) {
+	s.Cases = append(s.Cases, tc)
+	s.Tests++
+	s.elapsed += elapsed
+	switch {
+	case tc.Failure != nil:
+		s.Failures++
+	case tc.Skipped != nil:
+		s.Skipped++
+	}
+}
+
+// junitCase turns the check of a link, as found at ref, into a test case
+func junitCase(link types.LinkResult, ref types.Referrer, className string) junitTestCase {
+	tc := junitTestCase{
+		Name:      link.URL,
+		ClassName: className,
+		Time:      junitSeconds(link.ResponseTime),
+	}
+
+	switch link.Status {
+	case types.StatusDead, types.StatusMissingFragment, types.StatusError, types.StatusTimeout,
+		types.StatusRedirectLoop, types.StatusTooManyRedirect:
+		tc.Failure = &junitFailure{
+			Message: junitMessage(link),
+			Type:    string(link.Status),
+			Text:    junitDetails(link, ref),
+		}
+	case types.StatusSkipped, types.StatusBlockedByRobots, types.StatusRateLimited:
+		tc.Skipped = &junitSkipped{Message: string(link.Status)}
+	}
+	return tc
+}
+
+// junitMessage summarizes a failed link, e.g. "dead 404: Not Found"
+func junitMessage(link types.LinkResult) string {
+	message := string(link.Status)
+	if link.StatusCode > 0 {
+		message += fmt.Sprintf(" %d", link.StatusCode)
+	}
+	if link.Error != "" {
+		message += ": " + link.Error
+	}
+	return message
+}
+
+// junitDetails lists everything known about a failed link, one per line
+func junitDetails(link types.LinkResult, ref types.Referrer) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "URL: %s\n", link.URL)
+	fmt.Fprintf(&b, "Status: %s\n", link.Status)
+	if link.StatusCode > 0 {
+		fmt.Fprintf(&b, "Status code: %d\n", link.StatusCode)
+	}
+	if link.Error != "" {
+		fmt.Fprintf(&b, "Error: %s\n", link.Error)
+	}
+	if link.RedirectURL != "" {
+		fmt.Fprintf(&b, "Redirects: %s\n", formatChain(link))
+	}
+	fmt.Fprintf(&b, "Response time: %v\n", link.ResponseTime.Round(time.Millisecond))
+	switch {
+	case ref.Location != nil:
+		fmt.Fprintf(&b, "Found at: %s\n", ref.Location)
+	case ref.Page != "":
+		fmt.Fprintf(&b, "Found on: %s\n", ref.Page)
+	}
+	return b.String()
+}
+
+// linkHost names the host suite of a link; links to local files have none
+func linkHost(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return "local files"
+	}
+	return strings.ToLower(u.Host)
+}
+
+// junitSeconds formats a duration as JUnit's decimal seconds
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
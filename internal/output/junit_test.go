@@ -0,0 +1,133 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// newJUnitResult returns a check with one link of each outcome JUnit reports:
+// a passing start URL, a failure, an error and a skipped link
+func newJUnitResult() *types.CheckResult {
+	return &types.CheckResult{
+		Links: []types.LinkResult{
+			{URL: "https://example.com/", Status: types.StatusOK, StatusCode: 200},
+			{
+				URL:        "https://example.com/missing",
+				Status:     types.StatusDead,
+				StatusCode: 404,
+				Error:      "Not Found",
+				Referrers: []types.Referrer{
+					{Page: "docs/index.md", Location: &types.SourceLocation{File: "docs/index.md", Line: 3, Column: 5}},
+				},
+			},
+			{
+				URL:       "https://other.example.org/slow",
+				Status:    types.StatusTimeout,
+				Error:     "request timed out after 5s",
+				Referrers: []types.Referrer{{Page: "https://example.com/", Element: "a", Attribute: "href"}},
+			},
+			{
+				URL:       "https://other.example.org/private",
+				Status:    types.StatusSkipped,
+				Referrers: []types.Referrer{{Page: "https://example.com/about", Element: "a", Attribute: "href"}},
+			},
+		},
+	}
+}
+
+func TestJUnitFormatter(t *testing.T) {
+	type suiteCounts struct{ tests, failures, skipped int }
+
+	tests := []struct {
+		name       string
+		groupBy    types.GroupBy
+		incomplete bool
+		total      suiteCounts
+		suites     map[string]suiteCounts
+	}{
+		{
+			name:  "by host",
+			total: suiteCounts{4, 2, 1},
+			suites: map[string]suiteCounts{
+				"example.com":       {2, 1, 0},
+				"other.example.org": {2, 1, 1},
+			},
+		},
+		{
+			name:    "by page",
+			groupBy: types.GroupByPage,
+			total:   suiteCounts{4, 2, 1},
+			suites: map[string]suiteCounts{
+				"Start URLs":                {1, 0, 0},
+				"docs/index.md":             {1, 1, 0},
+				"https://example.com/":      {1, 1, 0},
+				"https://example.com/about": {1, 0, 1},
+			},
+		},
+		{
+			name:       "incomplete",
+			incomplete: true,
+			total:      suiteCounts{5, 3, 1},
+			suites: map[string]suiteCounts{
+				"example.com":       {2, 1, 0},
+				"other.example.org": {2, 1, 1},
+				"unlinked":          {1, 1, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newJUnitResult()
+			if tt.incomplete {
+				result.Incomplete = true
+				result.StopReason = types.StopCancelled
+			}
+
+			var buf bytes.Buffer
+			if err := (&JUnitFormatter{Options{GroupBy: tt.groupBy}}).Format(result, &buf); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			var root junitTestSuites
+			if err := xml.Unmarshal(buf.Bytes(), &root); err != nil {
+				t.Fatalf("Output is not valid XML: %v", err)
+			}
+			if got := (suiteCounts{root.Tests, root.Failures, root.Skipped}); got != tt.total {
+				t.Errorf("Expected totals %+v, got %+v", tt.total, got)
+			}
+			if len(root.Suites) != len(tt.suites) {
+				t.Errorf("Expected %d suites, got %d", len(tt.suites), len(root.Suites))
+			}
+
+			for _, suite := range root.Suites {
+				want, ok := tt.suites[suite.Name]
+				if !ok {
+					t.Errorf("Unexpected suite %q", suite.Name)
+					continue
+				}
+				if got := (suiteCounts{suite.Tests, suite.Failures, suite.Skipped}); got != want {
+					t.Errorf("Suite %q: expected %+v, got %+v", suite.Name, want, got)
+				}
+
+				// The counts must match the test cases actually written
+				var cases suiteCounts
+				for _, tc := range suite.Cases {
+					cases.tests++
+					if tc.Failure != nil {
+						cases.failures++
+					}
+					if tc.Skipped != nil {
+						cases.skipped++
+					}
+				}
+				if cases != want {
+					t.Errorf("Suite %q: expected test cases %+v, got %+v", suite.Name, want, cases)
+				}
+			}
+		})
+	}
+}
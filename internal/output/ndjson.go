@@ -0,0 +1,93 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// Streamer is a Formatter that can write link results while a check runs
+type Streamer interface {
+	Formatter
+	// Stream starts writing to w and returns the function that writes each
+	// link result. Format then completes the same output.
+	Stream(w io.Writer) func(link types.LinkResult)
+}
+
+// ndjsonLink is a link record: the link result tagged with its type
+type ndjsonLink struct {
+	Type string `json:"type"`
+	*types.LinkResult
+}
+
+// ndjsonSummary is the final record: the check result without its links,
+// which were written as records of their own
+type ndjsonSummary struct {
+	Type string `json:"type"`
+	*types.CheckResult
+	Links []types.LinkResult `json:"links,omitempty"`
+}
+
+// NDJSONFormatter formats output as JSON Lines: a "link" record for every
+// link result, followed by one "summary" record with the totals. When
+// streaming, link records are written the moment each link is checked, so
+// a killed check still leaves every finished result behind.
+//
+// A streamed link record lists the referrers known when the link was
+// checked; pages found to link to it later are not in the record. Links
+// that were never streamed, such as results restored from a journal when
+// resuming, are written by Format with all their referrers.
+type NDJSONFormatter struct {
+	mu   sync.Mutex
+	enc  *json.Encoder
+	sent map[string]bool
+	err  error
+}
+
+func (f *NDJSONFormatter) Stream(w io.Writer) func(link types.LinkResult) {
+	f.mu.Lock()
+	f.enc = json.NewEncoder(w)
+	f.sent = make(map[string]bool)
+	f.mu.Unlock()
+
+	return func(link types.LinkResult) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.writeLink(&link)
+	}
+}
+
+func (f *NDJSONFormatter) Format(result *types.CheckResult, w io.Writer) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.enc == nil {
+		f.enc = json.NewEncoder(w)
+	}
+	for i := range result.Links {
+		if !f.sent[result.Links[i].URL] {
+			f.writeLink(&result.Links[i])
+		}
+	}
+	if f.err != nil {
+		return f.err
+	}
+	return f.enc.Encode(ndjsonSummary{Type: "summary", CheckResult: result})
+}
+
+// writeLink writes a link record, keeping the first write error for Format
+// to report. The caller must hold f.mu.
+func (f *NDJSONFormatter) writeLink(link *types.LinkResult) {
+	if f.err != nil {
+		return
+	}
+	if err := f.enc.Encode(ndjsonLink{Type: "link", LinkResult: link}); err != nil {
+		f.err = err
+		return
+	}
+	if f.sent != nil {
+		f.sent[link.URL] = true
+	}
+}
@@ -0,0 +1,122 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// newNDJSONResult returns a finished check of three links
+func newNDJSONResult() *types.CheckResult {
+	return &types.CheckResult{
+		TotalChecked: 3,
+		TotalOK:      2,
+		TotalDead:    1,
+		Links: []types.LinkResult{
+			{URL: "https://example.com/", Status: types.StatusOK, StatusCode: 200},
+			{
+				URL:        "https://example.com/missing",
+				Status:     types.StatusDead,
+				StatusCode: 404,
+				Error:      "Not Found",
+				FoundOn:    "https://example.com/",
+				Referrers:  []types.Referrer{{Page: "https://example.com/", Element: "a", Attribute: "href"}},
+			},
+			{
+				URL:        "https://example.com/about",
+				Status:     types.StatusOK,
+				StatusCode: 200,
+				FoundOn:    "https://example.com/",
+				Referrers:  []types.Referrer{{Page: "https://example.com/", Element: "a", Attribute: "href"}},
+			},
+		},
+	}
+}
+
+func TestNDJSONFormatter(t *testing.T) {
+	// records decodes the output into its link URLs and summary records
+	records := func(t *testing.T, out string) (links []string, summaries []map[string]any) {
+		t.Helper()
+		for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+			var record map[string]any
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("Line is not valid JSON: %q", line)
+			}
+			switch record["type"] {
+			case "link":
+				links = append(links, record["url"].(string))
+			case "summary":
+				if _, ok := record["links"]; ok {
+					t.Error("Expected the summary record without its links")
+				}
+				summaries = append(summaries, record)
+			default:
+				t.Errorf("Unexpected record type %v", record["type"])
+			}
+		}
+		return links, summaries
+	}
+
+	t.Run("not streamed", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := (&NDJSONFormatter{}).Format(newNDJSONResult(), &buf); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+		links, summaries := records(t, buf.String())
+		if len(links) != 3 {
+			t.Errorf("Expected 3 link records, got %d", len(links))
+		}
+		if len(summaries) != 1 || summaries[0]["total_checked"] != float64(3) {
+			t.Errorf("Expected one summary record with the totals, got %v", summaries)
+		}
+	})
+
+	t.Run("streamed", func(t *testing.T) {
+		result := newNDJSONResult()
+		f := &NDJSONFormatter{}
+		var buf bytes.Buffer
+		stream := f.Stream(&buf)
+
+		// The first link was restored from a journal and never streamed
+		for _, link := range result.Links[1:] {
+			stream(link)
+		}
+		if streamed, summaries := records(t, buf.String()); len(streamed) != 2 || len(summaries) != 0 {
+			t.Fatalf("Expected 2 link records before Format, got %d and %d summaries", len(streamed), len(summaries))
+		}
+
+		if err := f.Format(result, &buf); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+		links, summaries := records(t, buf.String())
+		if len(links) != 3 {
+			t.Errorf("Expected every link written once, got %v", links)
+		}
+		if links[2] != result.Links[0].URL {
+			t.Errorf("Expected the link that was not streamed written by Format, got %v", links)
+		}
+		if len(summaries) != 1 {
+			t.Errorf("Expected exactly one summary record, got %d", len(summaries))
+		}
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		f := &NDJSONFormatter{}
+		stream := f.Stream(failingWriter{})
+		stream(newNDJSONResult().Links[0])
+		if err := f.Format(newNDJSONResult(), failingWriter{}); !errors.Is(err, errWriteFailed) {
+			t.Errorf("Expected the streaming write error from Format, got %v", err)
+		}
+	})
+}
+
+var errWriteFailed = errors.New("disk full")
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errWriteFailed }
@@ -0,0 +1,280 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sardonyx001/unlinked/internal/version"
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifRule describes one class of link failure
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	FullDescription  sarifMessage `json:"fullDescription"`
+	DefaultConfig    sarifConfig  `json:"defaultConfiguration"`
+	Help             sarifMessage `json:"help"`
+}
+
+type sarifConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifRules maps each failure status to its rule, in the order listed in the log
+var sarifRules = []struct {
+	status types.LinkStatus
+	rule   sarifRule
+}{
+	{types.StatusDead, sarifRule{
+		ID: "dead-link", Name: "DeadLink",
+		ShortDescription: sarifMessage{"Link target is missing"},
+		FullDescription:  sarifMessage{"The link points at a page or file that does not exist, or whose server answers with an error status such as 404 or 410."},
+		DefaultConfig:    sarifConfig{"error"},
+		Help:             sarifMessage{"Update the link to the page's new address, or remove it."},
+	}},
+	{types.StatusMissingFragment, sarifRule{
+		ID: "missing-fragment", Name: "MissingFragment",
+		ShortDescription: sarifMessage{"Link fragment does not exist on the target page"},
+		FullDescription:  sarifMessage{"The page exists, but no element on it has the id or name given by the link's #fragment."},
+		DefaultConfig:    sarifConfig{"error"},
+		Help:             sarifMessage{"Point the link at an existing heading or anchor, or drop the fragment."},
+	}},
+	{types.StatusError, sarifRule{
+		ID: "link-error", Name: "LinkError",
+		ShortDescription: sarifMessage{"Link target could not be reached"},
+		FullDescription:  sarifMessage{"Checking the link failed before a response arrived, for example because of a DNS, connection or TLS error."},
+		DefaultConfig:    sarifConfig{"warning"},
+		Help:             sarifMessage{"Check that the host still exists and serves HTTPS correctly."},
+	}},
+	{types.StatusTimeout, sarifRule{
+		ID: "link-timeout", Name: "LinkTimeout",
+		ShortDescription: sarifMessage{"Link target did not respond in time"},
+		FullDescription:  sarifMessage{"The server did not answer within the configured timeout."},
+		DefaultConfig:    sarifConfig{"warning"},
+		Help:             sarifMessage{"Retry later; links that keep timing out are likely dead."},
+	}},
+	{types.StatusRedirectLoop, sarifRule{
+		ID: "redirect-loop", Name: "RedirectLoop",
+		ShortDescription: sarifMessage{"Link redirects in a loop"},
+		FullDescription:  sarifMessage{"Following the link's redirects leads back to a URL already visited, so it never reaches a page."},
+		DefaultConfig:    sarifConfig{"error"},
+		Help:             sarifMessage{"Fix the redirect configuration of the target site, or link elsewhere."},
+	}},
+	{types.StatusTooManyRedirect, sarifRule{
+		ID: "too-many-redirects", Name: "TooManyRedirects",
+		ShortDescription: sarifMessage{"Link redirects too many times"},
+		FullDescription:  sarifMessage{"The link's redirect chain is longer than the configured maximum."},
+		DefaultConfig:    sarifConfig{"error"},
+		Help:             sarifMessage{"Link directly to the final destination of the chain."},
+	}},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool           `json:"executionSuccessful"`
+	StartTimeUTC        string         `json:"startTimeUtc"`
+	EndTimeUTC          string         `json:"endTimeUtc"`
+	Properties          map[string]any `json:"properties,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	RuleIndex  int             `json:"ruleIndex"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations,omitempty"`
+	Properties map[string]any  `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// SARIFFormatter formats output as a SARIF 2.1.0 log for code scanning tools.
+// Every occurrence of a dead, errored or timed-out link is a result, located
+// at the page or source file line it was found on.
+type SARIFFormatter struct{}
+
+func (f *SARIFFormatter) Format(result *types.CheckResult, w io.Writer) error {
+	rules := make([]sarifRule, len(sarifRules))
+	ruleIndex := make(map[types.LinkStatus]int, len(sarifRules))
+	for i, r := range sarifRules {
+		rules[i] = r.rule
+		ruleIndex[r.status] = i
+	}
+
+	results := make([]sarifResult, 0)
+	for _, link := range result.Links {
+		index, ok := ruleIndex[link.Status]
+		if !ok {
+			continue
+		}
+		rule := rules[index]
+
+		refs := link.Referrers
+		if len(refs) == 0 {
+			refs = []types.Referrer{{Page: link.FoundOn, Element: link.Element, Attribute: link.Attribute, Location: link.Location}}
+		}
+		for _, ref := range refs {
+			results = append(results, sarifResult{
+				RuleID:     rule.ID,
+				RuleIndex:  index,
+				Level:      rule.DefaultConfig.Level,
+				Message:    sarifMessage{sarifText(link, ref)},
+				Locations:  sarifLocations(link, ref),
+				Properties: sarifProperties(link),
+			})
+		}
+	}
+
+	invocation := sarifInvocation{
+		ExecutionSuccessful: !result.Incomplete,
+		StartTimeUTC:        result.StartTime.UTC().Format(time.RFC3339),
+		EndTimeUTC:          result.EndTime.UTC().Format(time.RFC3339),
+	}
+	if result.Incomplete {
+		invocation.Properties = map[string]any{"stopReason": result.StopReason}
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "unlinked",
+				Version:        version.Version,
+				InformationURI: "https://github.com/sardonyx001/unlinked",
+				Rules:          rules,
+			}},
+			Invocations: []sarifInvocation{invocation},
+			Results:     results,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// sarifText describes a failed link for the result message
+func sarifText(link types.LinkResult, ref types.Referrer) string {
+	var b strings.Builder
+	switch link.Status {
+	case types.StatusDead:
+		fmt.Fprintf(&b, "Dead link to %s", link.URL)
+		if link.StatusCode > 0 {
+			fmt.Fprintf(&b, " (HTTP %d)", link.StatusCode)
+		}
+	case types.StatusMissingFragment:
+		fmt.Fprintf(&b, "Missing fragment in link to %s", link.URL)
+	case types.StatusTimeout:
+		fmt.Fprintf(&b, "Link to %s timed out", link.URL)
+	default:
+		fmt.Fprintf(&b, "Broken link to %s", link.URL)
+	}
+	if ref.Text != "" {
+		fmt.Fprintf(&b, ", text %q", ref.Text)
+	}
+	if link.Error != "" {
+		fmt.Fprintf(&b, ": %s", link.Error)
+	}
+	return b.String()
+}
+
+// sarifLocations points at where a link was found: a source file line in
+// files mode, otherwise the page, or the link itself for start URLs
+func sarifLocations(link types.LinkResult, ref types.Referrer) []sarifLocation {
+	if loc := ref.Location; loc != nil {
+		return []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: fileURI(loc.File)},
+			Region:           &sarifRegion{StartLine: loc.Line, StartColumn: loc.Column},
+		}}}
+	}
+
+	uri := ref.Page
+	if uri == "" {
+		uri = link.URL
+	}
+	return []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: uri},
+	}}}
+}
+
+// fileURI turns a file path into a SARIF artifact URI: relative paths stay
+// relative to the directory the check ran in, absolute ones become file URIs
+func fileURI(path string) string {
+	uri := filepath.ToSlash(path)
+	if filepath.IsAbs(path) {
+		if !strings.HasPrefix(uri, "/") {
+			uri = "/" + uri
+		}
+		return "file://" + uri
+	}
+	return uri
+}
+
+// sarifProperties carries the details of a link check on a result
+func sarifProperties(link types.LinkResult) map[string]any {
+	props := map[string]any{
+		"url":    link.URL,
+		"status": link.Status,
+	}
+	if link.StatusCode > 0 {
+		props["statusCode"] = link.StatusCode
+	}
+	if link.Scope != "" {
+		props["scope"] = link.Scope
+	}
+	return props
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+func TestSARIFFormatter(t *testing.T) {
+	result := &types.CheckResult{
+		Links: []types.LinkResult{
+			{URL: "https://example.com/", Status: types.StatusOK, StatusCode: 200},
+			{
+				URL:        "https://example.com/missing",
+				Status:     types.StatusDead,
+				StatusCode: 404,
+				Error:      "Not Found",
+				Referrers: []types.Referrer{
+					{Page: "docs/index.md", Location: &types.SourceLocation{File: "docs/index.md", Line: 3, Column: 5}},
+					{Page: "docs/guide.md", Location: &types.SourceLocation{File: "docs/guide.md", Line: 12, Column: 1}},
+				},
+			},
+			{
+				URL:       "https://other.example.org/slow",
+				Status:    types.StatusTimeout,
+				Error:     "request timed out after 5s",
+				Referrers: []types.Referrer{{Page: "https://example.com/", Element: "a", Attribute: "href"}},
+			},
+			{
+				URL:       "https://other.example.org/private",
+				Status:    types.StatusSkipped,
+				Referrers: []types.Referrer{{Page: "https://example.com/", Element: "a", Attribute: "href"}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&SARIFFormatter{}).Format(result, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("Expected one SARIF %s run, got version %q with %d runs", sarifVersion, log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+
+	// One result per occurrence of a broken link: two for the dead link, one
+	// for the timeout, none for the ok and skipped links
+	if len(run.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d: %+v", len(run.Results), run.Results)
+	}
+
+	for _, res := range run.Results {
+		if res.RuleIndex < 0 || res.RuleIndex >= len(run.Tool.Driver.Rules) {
+			t.Fatalf("Result rule index %d is out of range", res.RuleIndex)
+		}
+		if rule := run.Tool.Driver.Rules[res.RuleIndex]; rule.ID != res.RuleID {
+			t.Errorf("Result rule %q does not match rule %q at index %d", res.RuleID, rule.ID, res.RuleIndex)
+		}
+	}
+
+	dead := run.Results[0]
+	if dead.RuleID != "dead-link" || dead.Level != "error" {
+		t.Errorf("Expected an error-level dead-link result, got %q at level %q", dead.RuleID, dead.Level)
+	}
+	loc := dead.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "docs/index.md" {
+		t.Errorf("Expected the dead link located in docs/index.md, got %q", loc.ArtifactLocation.URI)
+	}
+	if loc.Region == nil || loc.Region.StartLine != 3 || loc.Region.StartColumn != 5 {
+		t.Errorf("Expected region 3:5, got %+v", loc.Region)
+	}
+	if region := run.Results[1].Locations[0].PhysicalLocation.Region; region == nil || region.StartLine != 12 {
+		t.Errorf("Expected the second occurrence at line 12, got %+v", region)
+	}
+
+	timeout := run.Results[2]
+	if timeout.RuleID != "link-timeout" {
+		t.Errorf("Expected a link-timeout result, got %q", timeout.RuleID)
+	}
+	loc = timeout.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "https://example.com/" || loc.Region != nil {
+		t.Errorf("Expected the timeout located on its page without a region, got %+v", loc)
+	}
+}
@@ -0,0 +1,199 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+// linkGroup is a named list of links, as returned by the grouping helpers
+type linkGroup struct {
+	Name  string
+	Links []types.LinkResult
+}
+
+// statusOrder lists statuses from most to least urgent for byStatus
+var statusOrder = []types.LinkStatus{
+	types.StatusDead, types.StatusMissingFragment, types.StatusError, types.StatusTimeout,
+	types.StatusRedirectLoop, types.StatusTooManyRedirect, types.StatusRateLimited,
+	types.StatusBlockedByRobots, types.StatusRedirect, types.StatusSkipped, types.StatusOK,
+}
+
+// templateFuncs are the helpers available to report templates
+var templateFuncs = map[string]any{
+	"byStatus":   templateByStatus,
+	"byHost":     templateByHost,
+	"byReferrer": templateByReferrer,
+	"withStatus": templateWithStatus,
+	"broken":     templateBroken,
+	"host":       templateHost,
+	"duration":   templateDuration,
+	"ms":         func(d time.Duration) int64 { return d.Milliseconds() },
+	"markdown":   escapeMarkdown,
+	"json":       templateJSON,
+	"join":       strings.Join,
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+}
+
+// executor is a parsed text/template or html/template
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// TemplateFormatter formats output with a user-defined Go template. The
+// template is executed with the CheckResult as its data.
+type TemplateFormatter struct {
+	tmpl executor
+}
+
+// NewTemplateFormatter parses the template file at path. Files ending in
+// .html or .htm are parsed with html/template, which escapes values for
+// their context; any other file with text/template.
+func NewTemplateFormatter(path string) (*TemplateFormatter, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the template output format needs a template file (--template)")
+	}
+
+	name := filepath.Base(path)
+	var (
+		tmpl executor
+		err  error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		tmpl, err = htmltemplate.New(name).Funcs(templateFuncs).ParseFiles(path)
+	default:
+		tmpl, err = template.New(name).Funcs(templateFuncs).ParseFiles(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template: %w", err)
+	}
+	return &TemplateFormatter{tmpl: tmpl}, nil
+}
+
+func (f *TemplateFormatter) Format(result *types.CheckResult, w io.Writer) error {
+	return f.tmpl.Execute(w, result)
+}
+
+// templateByStatus groups links by status, most urgent first
+func templateByStatus(links []types.LinkResult) []linkGroup {
+	byStatus := groupByStatus(links)
+	groups := make([]linkGroup, 0, len(byStatus))
+	for _, status := range statusOrder {
+		if len(byStatus[status]) > 0 {
+			groups = append(groups, linkGroup{Name: string(status), Links: byStatus[status]})
+		}
+	}
+	return groups
+}
+
+// templateByHost groups links by the host they point at, hosts in order.
+// Links to local files are grouped under an empty host.
+func templateByHost(links []types.LinkResult) []linkGroup {
+	byHost := make(map[string][]types.LinkResult)
+	for _, link := range links {
+		host := templateHost(link.URL)
+		byHost[host] = append(byHost[host], link)
+	}
+	return sortedGroups(byHost)
+}
+
+// templateByReferrer groups links under every page they were found on, pages
+// in order. Links found on no page, such as start URLs, are grouped under an
+// empty name, listed first.
+func templateByReferrer(links []types.LinkResult) []linkGroup {
+	byPage := make(map[string][]types.LinkResult)
+	for _, link := range links {
+		pages := []string{link.FoundOn}
+		if len(link.Referrers) > 0 {
+			pages = pages[:0]
+			for _, ref := range link.Referrers {
+				if !slices.Contains(pages, ref.Page) {
+					pages = append(pages, ref.Page)
+				}
+			}
+		}
+		for _, page := range pages {
+			byPage[page] = append(byPage[page], link)
+		}
+	}
+	return sortedGroups(byPage)
+}
+
+func sortedGroups(grouped map[string][]types.LinkResult) []linkGroup {
+	groups := make([]linkGroup, 0, len(grouped))
+	for name, links := range grouped {
+		groups = append(groups, linkGroup{Name: name, Links: links})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// templateWithStatus returns the links with any of the given statuses
+func templateWithStatus(links []types.LinkResult, statuses ...string) []types.LinkResult {
+	var matched []types.LinkResult
+	for _, link := range links {
+		if slices.Contains(statuses, string(link.Status)) {
+			matched = append(matched, link)
+		}
+	}
+	return matched
+}
+
+// templateBroken returns the links that are dead, missing their fragment or
+// could not be checked
+func templateBroken(links []types.LinkResult) []types.LinkResult {
+	return templateWithStatus(links,
+		string(types.StatusDead), string(types.StatusMissingFragment), string(types.StatusError),
+		string(types.StatusTimeout), string(types.StatusRedirectLoop), string(types.StatusTooManyRedirect))
+}
+
+// templateHost returns the host of a URL, or nothing for a local file
+func templateHost(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// templateDuration rounds a duration for display, e.g. "1.25s" or "340ms"
+func templateDuration(d time.Duration) string {
+	switch {
+	case d >= time.Minute:
+		return d.Round(time.Second).String()
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	default:
+		return d.Round(time.Millisecond).String()
+	}
+}
+
+// templateJSON renders a value as JSON
+func templateJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// markdownReplacer backslash-escapes the characters Markdown gives meaning to
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "{", `\{`, "}", `\}`,
+	"[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "(", `\(`, ")", `\)`,
+	"#", `\#`, "|", `\|`, "!", `\!`,
+)
+
+// escapeMarkdown escapes text for use in Markdown, including table cells
+func escapeMarkdown(s string) string {
+	return markdownReplacer.Replace(s)
+}
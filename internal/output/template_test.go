@@ -0,0 +1,98 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sardonyx001/unlinked/pkg/types"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	const tmpl = `{{range byStatus .Links}}{{.Name}}={{len .Links}};{{end}}
+{{range byReferrer .Links}}[{{.Name}}]{{range .Links}} {{.URL}}{{end}}
+{{end}}{{range broken .Links}}{{markdown .URL}} {{.Error}}
+{{end}}`
+
+	result := &types.CheckResult{
+		Links: []types.LinkResult{
+			{URL: "https://example.com/", Status: types.StatusOK, StatusCode: 200},
+			{
+				URL:        "https://example.com/<missing>_(1)",
+				Status:     types.StatusDead,
+				StatusCode: 404,
+				Error:      "Not Found",
+				Referrers: []types.Referrer{
+					{Page: "docs/guide.md", Location: &types.SourceLocation{File: "docs/guide.md", Line: 12, Column: 1}},
+				},
+			},
+			{
+				URL:       "https://other.example.org/slow",
+				Status:    types.StatusTimeout,
+				Error:     "request timed out after 5s\nwhile reading headers",
+				Referrers: []types.Referrer{{Page: "https://example.com/", Element: "a", Attribute: "href"}},
+			},
+			{
+				URL:       "https://other.example.org/private",
+				Status:    types.StatusSkipped,
+				Referrers: []types.Referrer{{Page: "https://example.com/about", Element: "a", Attribute: "href"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{
+			file: "report.txt",
+			want: []string{
+				"dead=1;timeout=1;skipped=1;ok=1;",
+				"[] https://example.com/\n",
+				"[docs/guide.md] https://example.com/<missing>_(1)\n",
+				"[https://example.com/] https://other.example.org/slow\n",
+				"https://example.com/\\<missing\\>\\_\\(1\\) Not Found\n",
+				"https://other.example.org/slow request timed out after 5s\nwhile reading headers\n",
+			},
+		},
+		{
+			// html/template escapes every value for HTML on top of the helpers
+			file: "report.html",
+			want: []string{
+				"dead=1;timeout=1;skipped=1;ok=1;",
+				"[docs/guide.md] https://example.com/&lt;missing&gt;_(1)\n",
+				"[https://example.com/about] https://other.example.org/private\n",
+				"https://example.com/\\&lt;missing\\&gt;\\_\\(1\\) Not Found\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tmpl), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			f, err := NewTemplateFormatter(path)
+			if err != nil {
+				t.Fatalf("NewTemplateFormatter failed: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := f.Format(result, &buf); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+
+	if _, err := NewTemplateFormatter(""); err == nil {
+		t.Error("Expected an error without a template file")
+	}
+}
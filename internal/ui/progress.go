@@ -56,6 +56,8 @@ type ProgressModel struct {
 	width         int
 	done          bool
 	result        *types.CheckResult
+	cancel        func() // stops the check; nil quits immediately
+	stopping      bool
 }
 
 type Stats struct {
@@ -82,6 +84,13 @@ func NewProgressModel() ProgressModel {
 	}
 }
 
+// WithCancel returns a copy of the model that calls cancel when the user quits,
+// then waits for the partial result instead of exiting straight away
+func (m ProgressModel) WithCancel(cancel func()) ProgressModel {
+	m.cancel = cancel
+	return m
+}
+
 func (m ProgressModel) Init() tea.Cmd {
 	return m.spinner.Tick
 }
@@ -95,6 +104,12 @@ func (m ProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "q", "ctrl+c":
+			// First press stops the check gracefully, a second one quits
+			if m.cancel != nil && !m.stopping {
+				m.stopping = true
+				m.cancel()
+				return m, nil
+			}
 			return m, tea.Quit
 		}
 
@@ -105,11 +120,11 @@ func (m ProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.Status {
 		case types.StatusOK:
 			m.stats.OK++
-		case types.StatusDead:
+		case types.StatusDead, types.StatusMissingFragment:
 			m.stats.Dead++
 		case types.StatusRedirect:
 			m.stats.Redirects++
-		case types.StatusError, types.StatusTimeout:
+		case types.StatusError, types.StatusTimeout, types.StatusRedirectLoop, types.StatusTooManyRedirect:
 			m.stats.Errors++
 		}
 		return m, nil
@@ -158,7 +173,11 @@ func (m ProgressModel) View() string {
 	b.WriteString(m.renderStats())
 	b.WriteString("\n\n")
 
-	b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#6B7280")).Render("Press q or Ctrl+C to quit"))
+	if m.stopping {
+		b.WriteString(statusErrorStyle.Render("Stopping, waiting for in-flight checks... press q again to quit without results"))
+	} else {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#6B7280")).Render("Press q or Ctrl+C to quit"))
+	}
 
 	return b.String()
 }
@@ -171,8 +190,14 @@ func (m ProgressModel) renderStatus(status types.LinkStatus) string {
 		return statusDeadStyle.Render("✗ DEAD")
 	case types.StatusRedirect:
 		return lipgloss.NewStyle().Foreground(lipgloss.Color("#3B82F6")).Render("↻ REDIRECT")
-	case types.StatusError, types.StatusTimeout:
+	case types.StatusError, types.StatusTimeout, types.StatusRedirectLoop, types.StatusTooManyRedirect:
 		return statusErrorStyle.Render("! ERROR")
+	case types.StatusBlockedByRobots:
+		return urlStyle.Render("⊘ BLOCKED")
+	case types.StatusMissingFragment:
+		return statusDeadStyle.Render("# MISSING")
+	case types.StatusRateLimited:
+		return urlStyle.Render("⏳ LIMITED")
 	default:
 		return ""
 	}
@@ -196,10 +221,14 @@ func (m ProgressModel) renderStats() string {
 func (m ProgressModel) renderFinalReport() string {
 	var b strings.Builder
 
-	b.WriteString(titleStyle.Render("🎉 Check Complete!"))
+	result := m.result
+	if result.Incomplete {
+		b.WriteString(titleStyle.Render("⏹ Check Stopped - partial results"))
+	} else {
+		b.WriteString(titleStyle.Render("🎉 Check Complete!"))
+	}
 	b.WriteString("\n\n")
 
-	result := m.result
 	b.WriteString(fmt.Sprintf("Duration: %s\n", result.Duration.Round(1)))
 	b.WriteString(fmt.Sprintf("Total Checked: %d\n\n", result.TotalChecked))
 
@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // CheckMode defines how URLs should be checked
 type CheckMode string
@@ -10,6 +13,10 @@ const (
 	ModeSingle CheckMode = "single"
 	// ModeCrawler crawls the URL and checks all discovered links
 	ModeCrawler CheckMode = "crawler"
+	// ModeDirectory crawls a static site build on disk and checks all its links
+	ModeDirectory CheckMode = "directory"
+	// ModeFiles checks the links in Markdown and reStructuredText source files
+	ModeFiles CheckMode = "files"
 )
 
 // OutputFormat defines the output format for results
@@ -20,63 +27,413 @@ const (
 	FormatMarkdown  OutputFormat = "markdown"
 	FormatHTML      OutputFormat = "html"
 	FormatJSON      OutputFormat = "json"
+	FormatSARIF     OutputFormat = "sarif"
+	FormatJUnit     OutputFormat = "junit"
+	FormatCSV       OutputFormat = "csv"
+	FormatTSV       OutputFormat = "tsv"
+	FormatNDJSON    OutputFormat = "ndjson"
+	FormatTemplate  OutputFormat = "template"
+)
+
+// RequestMethod defines the HTTP method strategy used to check links
+type RequestMethod string
+
+const (
+	// MethodHead sends HEAD and falls back to GET on the configured status codes
+	MethodHead RequestMethod = "head"
+	// MethodGet always sends GET
+	MethodGet RequestMethod = "get"
+	// MethodRange sends GET with a Range header and reads only the first few bytes
+	MethodRange RequestMethod = "range"
+)
+
+// GroupBy selects how report formatters list links
+type GroupBy string
+
+const (
+	// GroupByStatus lists links under their status
+	GroupByStatus GroupBy = "status"
+	// GroupByPage lists the links that need fixing under each page they appear on
+	GroupByPage GroupBy = "page"
+)
+
+// LinkScope classifies a link relative to the site being checked
+type LinkScope string
+
+const (
+	ScopeInternal LinkScope = "internal"
+	ScopeExternal LinkScope = "external"
+)
+
+// CheckScope selects which links are checked
+type CheckScope string
+
+const (
+	CheckScopeAll      CheckScope = "all"
+	CheckScopeInternal CheckScope = "internal"
+	CheckScopeExternal CheckScope = "external"
 )
 
 // LinkStatus represents the status of a checked link
 type LinkStatus string
 
 const (
-	StatusOK       LinkStatus = "ok"
-	StatusDead     LinkStatus = "dead"
-	StatusRedirect LinkStatus = "redirect"
-	StatusTimeout  LinkStatus = "timeout"
-	StatusError    LinkStatus = "error"
-	StatusSkipped  LinkStatus = "skipped"
+	StatusOK              LinkStatus = "ok"
+	StatusDead            LinkStatus = "dead"
+	StatusRedirect        LinkStatus = "redirect"
+	StatusTimeout         LinkStatus = "timeout"
+	StatusError           LinkStatus = "error"
+	StatusSkipped         LinkStatus = "skipped"
+	StatusBlockedByRobots LinkStatus = "blocked_by_robots"
+	StatusMissingFragment LinkStatus = "missing_fragment"
+	StatusRedirectLoop    LinkStatus = "redirect_loop"
+	StatusTooManyRedirect LinkStatus = "too_many_redirects"
+	StatusRateLimited     LinkStatus = "rate_limited"
 )
 
+// RedirectType separates redirects whose links should be updated from those that should not
+type RedirectType string
+
+const (
+	// RedirectPermanent is a 301 or 308; the link should point at the new location
+	RedirectPermanent RedirectType = "permanent"
+	// RedirectTemporary is a 302, 303 or 307
+	RedirectTemporary RedirectType = "temporary"
+)
+
+// RedirectHop is one response in a redirect chain
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
 // LinkResult represents the result of checking a single link
 type LinkResult struct {
-	URL           string        `json:"url"`
-	Status        LinkStatus    `json:"status"`
-	StatusCode    int           `json:"status_code"`
-	Error         string        `json:"error,omitempty"`
-	RedirectURL   string        `json:"redirect_url,omitempty"`
-	FoundOn       string        `json:"found_on,omitempty"` // Parent URL where link was found
-	ResponseTime  time.Duration `json:"response_time"`
-	CheckedAt     time.Time     `json:"checked_at"`
-	ContentType   string        `json:"content_type,omitempty"`
-	ContentLength int64         `json:"content_length,omitempty"`
+	URL           string          `json:"url"`
+	Status        LinkStatus      `json:"status"`
+	StatusCode    int             `json:"status_code"`
+	Error         string          `json:"error,omitempty"`
+	RedirectURL   string          `json:"redirect_url,omitempty"`
+	Redirects     []RedirectHop   `json:"redirects,omitempty"`     // every redirect response, in order
+	RedirectType  RedirectType    `json:"redirect_type,omitempty"` // decided by the first hop
+	FoundOn       string          `json:"found_on,omitempty"`      // Parent URL where link was found
+	ResponseTime  time.Duration   `json:"response_time"`
+	CheckedAt     time.Time       `json:"checked_at"`
+	ContentType   string          `json:"content_type,omitempty"`
+	ContentLength int64           `json:"content_length,omitempty"`
+	Method        string          `json:"method,omitempty"` // HTTP method that produced the final verdict
+	Attempts      int             `json:"attempts,omitempty"`
+	Scope         LinkScope       `json:"scope,omitempty"`
+	Element       string          `json:"element,omitempty"`      // HTML element the link came from, e.g. "img"
+	Attribute     string          `json:"attribute,omitempty"`    // attribute the link came from, e.g. "src"
+	Rule          string          `json:"rule,omitempty"`         // status rule that decided Status, if any
+	Location      *SourceLocation `json:"location,omitempty"`     // position in the source file, in files mode
+	Referrers     []Referrer      `json:"referrers,omitempty"`    // every place the link was found, in the order found
+	NotFollowed   NoFollowReason  `json:"not_followed,omitempty"` // why a crawl did not visit the link's page
+}
+
+// NoFollowReason records which crawl policy kept a link's page from being visited
+type NoFollowReason string
+
+const (
+	// NoFollowRel means every occurrence of the link has rel="nofollow"
+	NoFollowRel NoFollowReason = "rel_nofollow"
+	// NoFollowRobots means the link only appears on pages whose robots meta
+	// tag or X-Robots-Tag header says nofollow
+	NoFollowRobots NoFollowReason = "robots_nofollow"
+	// NoFollowCanonical means the link only appears on pages that declare a
+	// different rel=canonical URL, which is crawled instead
+	NoFollowCanonical NoFollowReason = "non_canonical"
+)
+
+// Referrer is one place a link was found
+type Referrer struct {
+	Page      string          `json:"page"`                // page URL or source file
+	Text      string          `json:"text,omitempty"`      // anchor text, or alt text for images
+	Element   string          `json:"element,omitempty"`   // HTML element, e.g. "a"
+	Attribute string          `json:"attribute,omitempty"` // attribute, e.g. "href"
+	Location  *SourceLocation `json:"location,omitempty"`  // position in the source file, in files mode
+}
+
+// SourceLocation is the position of a link in a source file
+type SourceLocation struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`   // 1-based
+	Column int    `json:"column"` // 1-based, in characters
+}
+
+// String returns the location as file:line:column
+func (l SourceLocation) String() string {
+	return fmt.Sprintf("%s:%d:%d", l.File, l.Line, l.Column)
 }
 
 // CheckResult represents the complete result of a check operation
 type CheckResult struct {
-	StartTime     time.Time     `json:"start_time"`
-	EndTime       time.Time     `json:"end_time"`
-	TotalChecked  int           `json:"total_checked"`
-	TotalOK       int           `json:"total_ok"`
-	TotalDead     int           `json:"total_dead"`
-	TotalRedirect int           `json:"total_redirect"`
-	TotalErrors   int           `json:"total_errors"`
-	Links         []LinkResult  `json:"links"`
-	Duration      time.Duration `json:"duration"`
+	StartTime     time.Time      `json:"start_time"`
+	EndTime       time.Time      `json:"end_time"`
+	TotalChecked  int            `json:"total_checked"` // every link in the result, skipped ones included
+	TotalOK       int            `json:"total_ok"`
+	TotalDead     int            `json:"total_dead"`
+	TotalRedirect int            `json:"total_redirect"`
+	TotalErrors   int            `json:"total_errors"`
+	TotalBlocked  int            `json:"total_blocked"`
+	TotalLimited  int            `json:"total_rate_limited"`
+	TotalSkipped  int            `json:"total_skipped"` // links never requested, e.g. out of check_scope or over a budget
+	TotalMoved    int            `json:"total_moved"`   // links behind a permanent redirect
+	Internal      LinkTotals     `json:"internal"`
+	External      LinkTotals     `json:"external"`
+	Links         []LinkResult   `json:"links"`
+	Duration      time.Duration  `json:"duration"`
+	Incomplete    bool           `json:"incomplete"`            // the check stopped before every link was checked
+	StopReason    StopReason     `json:"stop_reason,omitempty"` // why an incomplete check stopped
+	Sitemap       *SitemapReport `json:"sitemap,omitempty"`     // crawler mode, when a sitemap was found
+}
+
+// SitemapReport compares the pages listed in sitemaps with the pages reachable by links
+type SitemapReport struct {
+	Sitemaps []string `json:"sitemaps"`           // sitemap files read
+	Pages    int      `json:"pages"`              // pages listed across all sitemaps
+	Orphans  []string `json:"orphans,omitempty"`  // listed in a sitemap but not linked from any crawled page
+	Unlisted []string `json:"unlisted,omitempty"` // reachable by links but missing from the sitemaps
+}
+
+// StopReason records why a check ended before it finished
+type StopReason string
+
+const (
+	// StopCancelled means the check was interrupted, e.g. by the user pressing q
+	StopCancelled StopReason = "cancelled"
+	// StopMaxPages means budget.max_pages pages were crawled
+	StopMaxPages StopReason = "max_pages"
+	// StopMaxLinks means budget.max_links links were checked
+	StopMaxLinks StopReason = "max_links"
+	// StopMaxDuration means the check ran for budget.max_duration
+	StopMaxDuration StopReason = "max_duration"
+	// StopMaxHostRequests means a host received budget.max_host_requests
+	// requests; later links to it were skipped
+	StopMaxHostRequests StopReason = "max_host_requests"
+	// StopMaxBytes means budget.max_bytes of responses were downloaded
+	StopMaxBytes StopReason = "max_bytes"
+)
+
+// LinkTotals holds per-status counts for a subset of checked links
+type LinkTotals struct {
+	Checked  int `json:"checked"` // links checked, not counting skipped ones
+	OK       int `json:"ok"`
+	Dead     int `json:"dead"`
+	Redirect int `json:"redirect"`
+	Errors   int `json:"errors"`
+	Blocked  int `json:"blocked"`
+	Limited  int `json:"rate_limited"`
+	Skipped  int `json:"skipped"`
+}
+
+// Add counts link towards the totals
+func (t *LinkTotals) Add(link LinkResult) {
+	if link.Status == StatusSkipped {
+		t.Skipped++
+		return
+	}
+	t.Checked++
+	switch link.Status {
+	case StatusOK:
+		t.OK++
+	case StatusDead, StatusMissingFragment:
+		t.Dead++
+	case StatusRedirect:
+		t.Redirect++
+	case StatusError, StatusTimeout, StatusRedirectLoop, StatusTooManyRedirect:
+		t.Errors++
+	case StatusBlockedByRobots:
+		t.Blocked++
+	case StatusRateLimited:
+		t.Limited++
+	}
 }
 
 // Config represents the application configuration
 type Config struct {
-	Mode              CheckMode    `mapstructure:"mode"`
-	OutputFormat      OutputFormat `mapstructure:"output_format"`
-	OutputFile        string       `mapstructure:"output_file"`
-	Concurrency       int          `mapstructure:"concurrency"`
-	Timeout           int          `mapstructure:"timeout"` // in seconds
-	MaxDepth          int          `mapstructure:"max_depth"`
-	FollowRedirects   bool         `mapstructure:"follow_redirects"`
-	CheckExternalOnly bool         `mapstructure:"check_external_only"`
-	UserAgent         string       `mapstructure:"user_agent"`
-	RespectRobotsTxt  bool         `mapstructure:"respect_robots_txt"`
-	AllowedDomains    []string     `mapstructure:"allowed_domains"`
-	IgnorePatterns    []string     `mapstructure:"ignore_patterns"`
-	Verbose           bool         `mapstructure:"verbose"`
-	ShowProgress      bool         `mapstructure:"show_progress"`
+	Mode              CheckMode       `mapstructure:"mode"`
+	OutputFormat      OutputFormat    `mapstructure:"output_format"`
+	OutputFile        string          `mapstructure:"output_file"`
+	GroupBy           GroupBy         `mapstructure:"group_by"` // report layout: status or page
+	CSV               CSVConfig       `mapstructure:"csv"`      // csv and tsv output
+	Template          string          `mapstructure:"template"` // template file of the template output format
+	Concurrency       int             `mapstructure:"concurrency"`
+	Timeout           int             `mapstructure:"timeout"` // in seconds
+	MaxDepth          int             `mapstructure:"max_depth"`
+	FollowRedirects   bool            `mapstructure:"follow_redirects"`
+	MaxRedirects      int             `mapstructure:"max_redirects"` // longest redirect chain followed
+	RequestMethod     RequestMethod   `mapstructure:"request_method"`
+	GetFallbackCodes  []int           `mapstructure:"get_fallback_codes"` // HEAD status codes that trigger a GET retry
+	RangeBytes        int             `mapstructure:"range_bytes"`        // bytes requested in range mode
+	Retry             RetryConfig     `mapstructure:"retry"`
+	RateLimit         RateLimitConfig `mapstructure:"rate_limit"`
+	Directory         DirectoryConfig `mapstructure:"directory"`
+	CheckExternalOnly bool            `mapstructure:"check_external_only"` // shorthand for check_scope: external
+	CheckScope        CheckScope      `mapstructure:"check_scope"`
+	InternalDomains   []string        `mapstructure:"internal_domains"` // defaults to the hosts of the seed URLs when crawling
+	UserAgent         string          `mapstructure:"user_agent"`
+	RespectRobotsTxt  bool            `mapstructure:"respect_robots_txt"`
+	AllowedDomains    []string        `mapstructure:"allowed_domains"`
+	Follow            FollowConfig    `mapstructure:"follow"` // which pages a crawl visits for more links
+	Check             ScopeRules      `mapstructure:"check"`  // which links are checked, on top of check_scope
+	IgnorePatterns    []string        `mapstructure:"ignore_patterns"`
+	Normalize         NormalizeConfig `mapstructure:"normalize"`
+	Budget            BudgetConfig    `mapstructure:"budget"`
+	StatusRules       []StatusRule    `mapstructure:"status_rules"`    // first match wins; unmatched codes use the 2xx/3xx/other default
+	CheckFragments    bool            `mapstructure:"check_fragments"` // verify #fragment targets exist on the page
+	UseSitemaps       bool            `mapstructure:"use_sitemaps"`    // seed crawls from sitemap.xml and robots.txt Sitemap lines
+	Sitemaps          []string        `mapstructure:"sitemaps"`        // sitemap URLs to read instead of discovering them
+	Extractors        []LinkExtractor `mapstructure:"extractors"`
+	Verbose           bool            `mapstructure:"verbose"`
+	ShowProgress      bool            `mapstructure:"show_progress"`
+}
+
+// ExtractorKind defines how an attribute value is turned into links
+type ExtractorKind string
+
+const (
+	// ExtractURL treats the whole attribute value as one URL
+	ExtractURL ExtractorKind = "url"
+	// ExtractSrcset parses a srcset candidate list
+	ExtractSrcset ExtractorKind = "srcset"
+	// ExtractRefresh parses the URL out of a meta refresh content value
+	ExtractRefresh ExtractorKind = "refresh"
+)
+
+// LinkExtractor pulls links out of one attribute of matching HTML elements
+type LinkExtractor struct {
+	Element   string        `mapstructure:"element"`   // CSS selector for the element, e.g. "img" or "meta[http-equiv=refresh]"
+	Attribute string        `mapstructure:"attribute"` // attribute holding the link, e.g. "src"
+	Kind      ExtractorKind `mapstructure:"kind"`      // defaults to url
+	Follow    bool          `mapstructure:"follow"`    // visit the target to discover more links (crawler mode)
+}
+
+// DefaultExtractors returns the link extractors used when none are configured
+func DefaultExtractors() []LinkExtractor {
+	return []LinkExtractor{
+		{Element: "a", Attribute: "href", Kind: ExtractURL, Follow: true},
+		{Element: "area", Attribute: "href", Kind: ExtractURL, Follow: true},
+		{Element: "iframe", Attribute: "src", Kind: ExtractURL},
+		{Element: "frame", Attribute: "src", Kind: ExtractURL},
+		{Element: "img", Attribute: "src", Kind: ExtractURL},
+		{Element: "img", Attribute: "srcset", Kind: ExtractSrcset},
+		{Element: "source", Attribute: "src", Kind: ExtractURL},
+		{Element: "source", Attribute: "srcset", Kind: ExtractSrcset},
+		{Element: "video", Attribute: "src", Kind: ExtractURL},
+		{Element: "video", Attribute: "poster", Kind: ExtractURL},
+		{Element: "audio", Attribute: "src", Kind: ExtractURL},
+		{Element: "track", Attribute: "src", Kind: ExtractURL},
+		{Element: "embed", Attribute: "src", Kind: ExtractURL},
+		{Element: "object", Attribute: "data", Kind: ExtractURL},
+		{Element: "script", Attribute: "src", Kind: ExtractURL},
+		{Element: "link:not([rel~=preconnect]):not([rel~=dns-prefetch])", Attribute: "href", Kind: ExtractURL},
+		{Element: "meta[http-equiv=refresh i]", Attribute: "content", Kind: ExtractRefresh, Follow: true},
+	}
+}
+
+// RetryConfig controls how failed requests are retried
+type RetryConfig struct {
+	MaxAttempts       int      `mapstructure:"max_attempts"`    // total attempts, including the first
+	InitialBackoff    int      `mapstructure:"initial_backoff"` // in milliseconds
+	MaxBackoff        int      `mapstructure:"max_backoff"`     // in milliseconds
+	Multiplier        float64  `mapstructure:"multiplier"`
+	Jitter            float64  `mapstructure:"jitter"` // fraction of the backoff randomized, 0-1
+	RetryOnStatus     []int    `mapstructure:"retry_on_status"`
+	RetryOnErrors     []string `mapstructure:"retry_on_errors"` // timeout, connection, dns, tls
+	RespectRetryAfter bool     `mapstructure:"respect_retry_after"`
+	MaxRetryAfter     int      `mapstructure:"max_retry_after"` // in seconds
+}
+
+// RateLimitConfig controls per-host request pacing
+type RateLimitConfig struct {
+	RequestsPerSecond float64         `mapstructure:"requests_per_second"` // per host, 0 = unlimited
+	MaxInFlight       int             `mapstructure:"max_in_flight"`       // per host, 0 = unlimited
+	Adaptive          bool            `mapstructure:"adaptive"`            // slow down a host after 429 responses
+	Hosts             []HostRateLimit `mapstructure:"hosts"`
+}
+
+// HostRateLimit overrides the rate limit for hosts matching a domain glob
+type HostRateLimit struct {
+	Domain            string  `mapstructure:"domain"` // e.g. "github.com" or "*.github.com"
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	MaxInFlight       int     `mapstructure:"max_in_flight"`
+}
+
+// StatusRule maps responses to a LinkStatus. Every criterion that is set must
+// match; codes and ranges match if the status code is in either.
+type StatusRule struct {
+	Name    string     `mapstructure:"name"`    // reported on matching results, defaults to a summary of the rule
+	Codes   []int      `mapstructure:"codes"`   // e.g. [401, 403]
+	Ranges  []string   `mapstructure:"ranges"`  // inclusive, e.g. "500-599"
+	Hosts   []string   `mapstructure:"hosts"`   // domain globs, e.g. "*.linkedin.com"
+	Pattern string     `mapstructure:"pattern"` // regular expression matched against the URL
+	Status  LinkStatus `mapstructure:"status"`
+}
+
+// ScopeRules select URLs by path prefix or glob. Patterns starting with "/"
+// match the URL path and others the whole URL; patterns without * or ?
+// match as prefixes.
+type ScopeRules struct {
+	Include          []string `mapstructure:"include"`            // e.g. "/docs/"; empty includes everything
+	Exclude          []string `mapstructure:"exclude"`            // e.g. "/docs/archive/" or "*.pdf"; wins over include
+	MaxQueryVariants int      `mapstructure:"max_query_variants"` // distinct query strings per path, 0 = unlimited
+}
+
+// FollowConfig decides which pages a crawl visits for more links. Only
+// internal pages are followed; links on them are checked regardless.
+type FollowConfig struct {
+	ScopeRules       `mapstructure:",squash"`
+	Subdomains       bool `mapstructure:"subdomains"`        // also follow subdomains of internal domains
+	RespectNofollow  bool `mapstructure:"respect_nofollow"`  // skip rel="nofollow" links and pages marked robots nofollow
+	RespectCanonical bool `mapstructure:"respect_canonical"` // crawl a page's rel=canonical URL instead of following its links
+}
+
+// CSVConfig selects what the csv and tsv output formats write
+type CSVConfig struct {
+	Columns []string `mapstructure:"columns"` // LinkResult fields by JSON name; empty writes url, status, status_code, found_on, error
+	Header  bool     `mapstructure:"header"`  // write a header row of column names
+}
+
+// BudgetConfig caps the work a check may do; 0 means unlimited. A check
+// that reaches a limit stops early and is reported as incomplete.
+type BudgetConfig struct {
+	MaxPages        int   `mapstructure:"max_pages"`         // pages crawled for links
+	MaxLinks        int   `mapstructure:"max_links"`         // links checked
+	MaxDuration     int   `mapstructure:"max_duration"`      // in seconds
+	MaxHostRequests int   `mapstructure:"max_host_requests"` // requests to any one host
+	MaxBytes        int64 `mapstructure:"max_bytes"`         // response bytes downloaded
+}
+
+// TrailingSlash selects how URL normalization treats a trailing slash
+type TrailingSlash string
+
+const (
+	// TrailingSlashKeep leaves paths as written
+	TrailingSlashKeep TrailingSlash = "keep"
+	// TrailingSlashAdd appends a slash to paths whose last segment has no extension
+	TrailingSlashAdd TrailingSlash = "add"
+	// TrailingSlashStrip removes the trailing slash of every path but the root
+	TrailingSlashStrip TrailingSlash = "strip"
+)
+
+// NormalizeConfig controls how URLs are canonicalized before links are
+// deduplicated and pages visited. Scheme and host case, default ports and
+// dot segments are always normalized.
+type NormalizeConfig struct {
+	StripParams   []string      `mapstructure:"strip_params"`   // query parameters to drop, globs such as "utm_*"
+	SortParams    bool          `mapstructure:"sort_params"`    // order query parameters by name
+	TrailingSlash TrailingSlash `mapstructure:"trailing_slash"` // keep, add or strip
+	StripFragment bool          `mapstructure:"strip_fragment"` // treat links differing only in #fragment as one
+}
+
+// DirectoryConfig describes a static site build checked from disk
+type DirectoryConfig struct {
+	Root       string   `mapstructure:"root"`        // build output directory, e.g. "./public"
+	BaseURL    string   `mapstructure:"base_url"`    // URL the site is published at; links under it are resolved to files
+	IndexFiles []string `mapstructure:"index_files"` // files served for a directory URL, in order
+	CleanURLs  bool     `mapstructure:"clean_urls"`  // serve /about from about.html
 }
 
 // DefaultConfig returns a configuration with sensible defaults
@@ -84,12 +441,51 @@ func DefaultConfig() *Config {
 	return &Config{
 		Mode:             ModeSingle,
 		OutputFormat:     FormatPlaintext,
+		GroupBy:          GroupByStatus,
 		Concurrency:      10,
 		Timeout:          30,
 		MaxDepth:         3,
 		FollowRedirects:  true,
+		MaxRedirects:     10,
+		RequestMethod:    MethodHead,
+		GetFallbackCodes: []int{403, 405, 501},
+		RangeBytes:       512,
+		CheckScope:       CheckScopeAll,
+		Extractors:       DefaultExtractors(),
+		Retry: RetryConfig{
+			MaxAttempts:       3,
+			InitialBackoff:    500,
+			MaxBackoff:        10000,
+			Multiplier:        2,
+			Jitter:            0.2,
+			RetryOnStatus:     []int{408, 429, 500, 502, 503, 504},
+			RetryOnErrors:     []string{"timeout", "connection"},
+			RespectRetryAfter: true,
+			MaxRetryAfter:     60,
+		},
+		RateLimit: RateLimitConfig{
+			Adaptive: true,
+		},
+		CSV: CSVConfig{
+			Header: true,
+		},
+		Follow: FollowConfig{
+			RespectNofollow:  true,
+			RespectCanonical: true,
+		},
+		Normalize: NormalizeConfig{
+			StripParams:   []string{"utm_*", "fbclid", "gclid"},
+			TrailingSlash: TrailingSlashKeep,
+		},
+		Directory: DirectoryConfig{
+			BaseURL:    "http://localhost/",
+			IndexFiles: []string{"index.html"},
+			CleanURLs:  true,
+		},
 		UserAgent:        "Unlinked/1.0 (Dead Link Checker)",
 		RespectRobotsTxt: true,
+		CheckFragments:   true,
+		UseSitemaps:      true,
 		Verbose:          false,
 		ShowProgress:     true,
 	}
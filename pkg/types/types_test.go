@@ -65,6 +65,11 @@ func TestLinkStatus(t *testing.T) {
 		{"Timeout status", StatusTimeout, "timeout"},
 		{"Error status", StatusError, "error"},
 		{"Skipped status", StatusSkipped, "skipped"},
+		{"Blocked by robots status", StatusBlockedByRobots, "blocked_by_robots"},
+		{"Missing fragment status", StatusMissingFragment, "missing_fragment"},
+		{"Redirect loop status", StatusRedirectLoop, "redirect_loop"},
+		{"Too many redirects status", StatusTooManyRedirect, "too_many_redirects"},
+		{"Rate limited status", StatusRateLimited, "rate_limited"},
 	}
 
 	for _, tt := range tests {
@@ -91,6 +96,12 @@ func TestOutputFormat(t *testing.T) {
 		FormatMarkdown:  "markdown",
 		FormatHTML:      "html",
 		FormatJSON:      "json",
+		FormatSARIF:     "sarif",
+		FormatJUnit:     "junit",
+		FormatCSV:       "csv",
+		FormatTSV:       "tsv",
+		FormatNDJSON:    "ndjson",
+		FormatTemplate:  "template",
 	}
 
 	for format, expected := range formats {